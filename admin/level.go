@@ -0,0 +1,271 @@
+/*
+Package admin implements HTTP handlers for managing loggers of running service.
+
+LevelHandler exposes logging levels of registered loggers:
+
+	handler := admin.NewLevelHandler()
+	handler.Register("app", logger)
+
+	http.Handle("/loggers/", http.StripPrefix("/loggers/", handler))
+
+	GET /loggers/       lists all registered loggers
+	GET /loggers/app    returns current level of logger named "app"
+	PUT /loggers/app    changes level of logger named "app"
+
+PUT request body is a JSON object with name or numeric value of registered
+level and optional TTL after which previous level is restored:
+
+	{"level": "VERBOSE", "ttl": "5m"}
+*/
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomakado/logo/log"
+)
+
+// LevelHandler is an http.Handler exposing logging levels of registered loggers.
+type LevelHandler struct {
+	mx      sync.Mutex
+	loggers map[string]*levelEntry
+}
+
+// levelEntry is a registered logger with its temporary level override.
+type levelEntry struct {
+	logger *log.Logger
+
+	revert     *time.Timer
+	revertTo   log.Level
+	expiresAt  time.Time
+	generation uint64
+}
+
+// NewLevelHandler creates a new instance of LevelHandler without registered loggers.
+func NewLevelHandler() *LevelHandler {
+	return &LevelHandler{
+		loggers: make(map[string]*levelEntry),
+	}
+}
+
+// Register makes level of given logger available under given name.
+// Logger registered earlier with the same name is replaced.
+func (h *LevelHandler) Register(name string, logger *log.Logger) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if entry, ok := h.loggers[name]; ok {
+		entry.stopRevert()
+	}
+
+	h.loggers[name] = &levelEntry{logger: logger}
+}
+
+// ServeHTTP serves GET and PUT requests on level of logger named by request path.
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		h.list(w)
+	case r.Method == http.MethodGet:
+		h.get(w, name)
+	case r.Method == http.MethodPut && name != "":
+		h.put(w, r, name)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+// LevelState describes current level of registered logger.
+type LevelState struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	Value uint8  `json:"value"`
+
+	Override *LevelOverride `json:"override,omitempty"`
+}
+
+// LevelOverride describes temporary level set with TTL.
+type LevelOverride struct {
+	RevertTo  string    `json:"revert_to"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// levelRequest is a body of PUT request. Level is either a string
+// or a number, TTL is a duration string like "5m".
+type levelRequest struct {
+	Level json.RawMessage `json:"level"`
+	TTL   string          `json:"ttl,omitempty"`
+}
+
+func (h *LevelHandler) list(w http.ResponseWriter) {
+	h.mx.Lock()
+
+	states := make([]LevelState, 0, len(h.loggers))
+	for name, entry := range h.loggers {
+		states = append(states, entry.state(name))
+	}
+
+	h.mx.Unlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, states)
+}
+
+func (h *LevelHandler) get(w http.ResponseWriter, name string) {
+	h.mx.Lock()
+
+	entry, ok := h.loggers[name]
+	if !ok {
+		h.mx.Unlock()
+		writeError(w, http.StatusNotFound, fmt.Errorf("logger %q is not registered", name))
+
+		return
+	}
+
+	state := entry.state(name)
+	h.mx.Unlock()
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (h *LevelHandler) put(w http.ResponseWriter, r *http.Request, name string) {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return
+	}
+
+	level, err := parseLevel(req.Level)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl %q", req.TTL))
+			return
+		}
+	}
+
+	h.mx.Lock()
+
+	entry, ok := h.loggers[name]
+	if !ok {
+		h.mx.Unlock()
+		writeError(w, http.StatusNotFound, fmt.Errorf("logger %q is not registered", name))
+
+		return
+	}
+
+	if ttl > 0 {
+		h.override(entry, level, ttl)
+	} else {
+		entry.stopRevert()
+		entry.logger.SetLevel(level)
+	}
+
+	state := entry.state(name)
+	h.mx.Unlock()
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+// override sets level of entry's logger and schedules restoring of level
+// the logger had before the first of consecutive overrides.
+func (h *LevelHandler) override(entry *levelEntry, level log.Level, ttl time.Duration) {
+	if entry.revert == nil {
+		entry.revertTo = entry.logger.Level()
+	}
+
+	entry.stopRevert()
+	entry.generation++
+	generation := entry.generation
+
+	entry.logger.SetLevel(level)
+	entry.expiresAt = time.Now().Add(ttl)
+	entry.revert = time.AfterFunc(ttl, func() {
+		h.mx.Lock()
+		defer h.mx.Unlock()
+
+		if entry.generation != generation || entry.revert == nil {
+			return
+		}
+
+		entry.logger.SetLevel(entry.revertTo)
+		entry.revert = nil
+	})
+}
+
+func (e *levelEntry) stopRevert() {
+	if e.revert == nil {
+		return
+	}
+
+	e.revert.Stop()
+	e.revert = nil
+}
+
+func (e *levelEntry) state(name string) LevelState {
+	level := e.logger.Level()
+
+	state := LevelState{
+		Name:  name,
+		Level: level.String(),
+		Value: level.Uint8(),
+	}
+
+	if e.revert != nil {
+		state.Override = &LevelOverride{
+			RevertTo:  e.revertTo.String(),
+			ExpiresAt: e.expiresAt,
+		}
+	}
+
+	return state
+}
+
+// parseLevel converts level name or numeric value to registered level.
+func parseLevel(raw json.RawMessage) (log.Level, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return log.Level{}, fmt.Errorf("invalid level %s", raw)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return log.ParseLevel(v)
+	case float64:
+		return log.ParseLevel(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return log.Level{}, fmt.Errorf("invalid level %s", raw)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
@@ -0,0 +1,156 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/admin"
+	"github.com/tomakado/logo/log"
+)
+
+func newTestHandler() (*admin.LevelHandler, *log.Logger) {
+	logger := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{})
+
+	handler := admin.NewLevelHandler()
+	handler.Register("app", logger)
+
+	return handler, logger
+}
+
+func serve(t *testing.T, h http.Handler, method, path, body string) (int, admin.LevelState) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	var state admin.LevelState
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	}
+
+	return rec.Code, state
+}
+
+func TestLevelHandler_Get(t *testing.T) {
+	handler, _ := newTestHandler()
+
+	t.Run("registered logger", func(t *testing.T) {
+		status, state := serve(t, handler, http.MethodGet, "/app", "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, admin.LevelState{Name: "app", Level: "IMPORTANT", Value: 20}, state)
+	})
+
+	t.Run("unknown logger", func(t *testing.T) {
+		status, _ := serve(t, handler, http.MethodGet, "/db", "")
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		handler.Register("db", log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var states []admin.LevelState
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+		assert.Equal(t, []admin.LevelState{
+			{Name: "app", Level: "IMPORTANT", Value: 20},
+			{Name: "db", Level: "VERBOSE", Value: 10},
+		}, states)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		status, _ := serve(t, handler, http.MethodDelete, "/app", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+	})
+}
+
+func TestLevelHandler_Put(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		status   int
+		expected log.Level
+	}{
+		{name: "name", body: `{"level":"VERBOSE"}`, status: http.StatusOK, expected: log.LevelVerbose},
+		{name: "lowercase name", body: `{"level":"verbose"}`, status: http.StatusOK, expected: log.LevelVerbose},
+		{name: "number", body: `{"level":10}`, status: http.StatusOK, expected: log.LevelVerbose},
+		{name: "numeric string", body: `{"level":"10"}`, status: http.StatusOK, expected: log.LevelVerbose},
+		{name: "unknown name", body: `{"level":"DEBUG"}`, status: http.StatusBadRequest, expected: log.LevelImportant},
+		{name: "unregistered number", body: `{"level":42}`, status: http.StatusBadRequest, expected: log.LevelImportant},
+		{name: "out of range", body: `{"level":300}`, status: http.StatusBadRequest, expected: log.LevelImportant},
+		{name: "invalid body", body: `level=VERBOSE`, status: http.StatusBadRequest, expected: log.LevelImportant},
+		{name: "invalid ttl", body: `{"level":"VERBOSE","ttl":"soon"}`, status: http.StatusBadRequest, expected: log.LevelImportant},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			handler, logger := newTestHandler()
+
+			status, _ := serve(t, handler, http.MethodPut, "/app", tc.body)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.expected, logger.Level())
+		})
+	}
+
+	t.Run("unknown logger", func(t *testing.T) {
+		handler, _ := newTestHandler()
+
+		status, _ := serve(t, handler, http.MethodPut, "/db", `{"level":"VERBOSE"}`)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestLevelHandler_Override(t *testing.T) {
+	t.Run("reverts after ttl", func(t *testing.T) {
+		handler, logger := newTestHandler()
+
+		status, state := serve(t, handler, http.MethodPut, "/app", `{"level":"VERBOSE","ttl":"50ms"}`)
+		require.Equal(t, http.StatusOK, status)
+		require.NotNil(t, state.Override)
+		assert.Equal(t, "IMPORTANT", state.Override.RevertTo)
+		assert.Equal(t, log.LevelVerbose, logger.Level())
+
+		assert.Eventually(t, func() bool {
+			return logger.Level() == log.LevelImportant
+		}, time.Second, 10*time.Millisecond)
+
+		_, state = serve(t, handler, http.MethodGet, "/app", "")
+		assert.Nil(t, state.Override)
+	})
+
+	t.Run("consecutive overrides revert to original level", func(t *testing.T) {
+		handler, logger := newTestHandler()
+
+		serve(t, handler, http.MethodPut, "/app", `{"level":"VERBOSE","ttl":"1h"}`)
+		_, state := serve(t, handler, http.MethodPut, "/app", `{"level":"IMPORTANT","ttl":"50ms"}`)
+		require.NotNil(t, state.Override)
+		assert.Equal(t, "IMPORTANT", state.Override.RevertTo)
+
+		logger.SetLevel(log.LevelVerbose)
+		assert.Eventually(t, func() bool {
+			return logger.Level() == log.LevelImportant
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("permanent level cancels override", func(t *testing.T) {
+		handler, logger := newTestHandler()
+
+		serve(t, handler, http.MethodPut, "/app", `{"level":"VERBOSE","ttl":"20ms"}`)
+		_, state := serve(t, handler, http.MethodPut, "/app", `{"level":"VERBOSE"}`)
+		assert.Nil(t, state.Override)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, log.LevelVerbose, logger.Level())
+	})
+}
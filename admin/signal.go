@@ -0,0 +1,83 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package admin
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tomakado/logo/log"
+)
+
+// ToggleLevelOnSignal switches logger between LevelVerbose and LevelImportant
+// every time one of given signals is received. SIGUSR1 is used if no signals
+// are given. See CycleLevelOnSignal for details.
+func ToggleLevelOnSignal(ctx context.Context, logger *log.Logger, sigs ...os.Signal) <-chan struct{} {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGUSR1}
+	}
+
+	return CycleLevelOnSignal(ctx, logger, []log.Level{log.LevelVerbose, log.LevelImportant}, sigs...)
+}
+
+// CycleLevelOnSignal switches logger to the level following the current one
+// in given list every time one of given signals is received. All registered
+// levels are cycled through if the list is empty and SIGUSR2 is used if no
+// signals are given. Logger with level missing in the list is switched to
+// the first level. Every transition is logged as important event.
+//
+// Signals are handled in background until ctx is done, returned channel is
+// closed after handling is stopped.
+func CycleLevelOnSignal(ctx context.Context, logger *log.Logger, levels []log.Level, sigs ...os.Signal) <-chan struct{} {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGUSR2}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				from := logger.Level()
+				to := nextLevel(levels, from)
+				logger.SetLevel(to)
+
+				logger.ImportantX(ctx, "logging level changed by signal", log.Extra{
+					"signal": sig.String(),
+					"from":   from.String(),
+					"to":     to.String(),
+				})
+			}
+		}
+	}()
+
+	return done
+}
+
+// nextLevel returns level following given current level in list
+// or in registered levels if the list is empty.
+func nextLevel(levels []log.Level, current log.Level) log.Level {
+	if len(levels) == 0 {
+		levels = log.Levels()
+	}
+
+	for i, level := range levels {
+		if level.Uint8() == current.Uint8() {
+			return levels[(i+1)%len(levels)]
+		}
+	}
+
+	return levels[0]
+}
@@ -0,0 +1,101 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package admin_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/admin"
+	"github.com/tomakado/logo/log"
+)
+
+func waitLevel(t *testing.T, logger *log.Logger, expected log.Level) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		return logger.Level() == expected
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestToggleLevelOnSignal(t *testing.T) {
+	var (
+		mx     sync.Mutex
+		events []log.Event
+	)
+
+	logger := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{})
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		events = append(events, *e)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := admin.ToggleLevelOnSignal(ctx, logger)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	waitLevel(t, logger, log.LevelVerbose)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	waitLevel(t, logger, log.LevelImportant)
+
+	cancel()
+	<-done
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	require.Len(t, events, 2)
+	assert.Equal(t, log.LevelImportant, events[0].Level)
+	assert.Equal(t, log.Extra{"signal": "user defined signal 1", "from": "IMPORTANT", "to": "VERBOSE"}, events[0].Extra)
+	assert.Equal(t, log.Extra{"signal": "user defined signal 1", "from": "VERBOSE", "to": "IMPORTANT"}, events[1].Extra)
+}
+
+func TestCycleLevelOnSignal(t *testing.T) {
+	levelCritical := log.NewLevel(30, "CRITICAL")
+	logger := log.NewLogger(log.NewLevel(15, "CUSTOM"), ioutil.Discard, &log.JSONFormatter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := admin.CycleLevelOnSignal(ctx, logger, []log.Level{log.LevelVerbose, log.LevelImportant, levelCritical})
+
+	for _, expected := range []log.Level{log.LevelVerbose, log.LevelImportant, levelCritical, log.LevelVerbose} {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+		waitLevel(t, logger, expected)
+	}
+
+	cancel()
+	<-done
+
+	// registered levels are used when list is empty
+	ctx, cancel = context.WithCancel(context.Background())
+	done = admin.CycleLevelOnSignal(ctx, logger, nil)
+
+	for _, expected := range []log.Level{log.LevelImportant, log.LevelVerbose} {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+		waitLevel(t, logger, expected)
+	}
+
+	// keep the process alive when signal is not handled anymore
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR2)
+	defer signal.Stop(ch)
+
+	cancel()
+	<-done
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	<-ch
+
+	assert.Equal(t, log.LevelVerbose, logger.Level())
+}
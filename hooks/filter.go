@@ -18,7 +18,7 @@ func FilteredHook(h log.Hook, filter Filter) log.Hook {
 }
 
 // Filter is a function that returns true in case hook should be called
-// and false otherwise.
+// and false otherwise. It can also be used as log.Sink filter.
 type Filter func(e *log.Event) bool
 
 // LevelBoundsFilter returns filter based on given logging level bounds.
@@ -1,6 +1,7 @@
 package hooks_test
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"testing"
@@ -89,3 +90,23 @@ func TestLevelFilter(t *testing.T) {
 	logger.Important(ctx, "really important")
 	assert.True(t, hookCalled)
 }
+
+func TestFilter_Sink(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := log.NewSinkLogger(log.LevelVerbose, []log.Sink{
+		{
+			Output:    &buf,
+			Formatter: &log.JSONFormatter{},
+			Filter:    hooks.LevelBoundsFilter(log.LevelVerbose, log.LevelVerbose),
+		},
+	})
+
+	ctx := context.Background()
+
+	logger.Important(ctx, "important")
+	assert.Equal(t, 0, buf.Len())
+
+	logger.Verbose(ctx, "verbose")
+	assert.Contains(t, buf.String(), "verbose")
+}
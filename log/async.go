@@ -0,0 +1,297 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy defines what asynchronous logger does with event
+// being written when its queue is full.
+type OverflowPolicy uint8
+
+// Supported overflow policies.
+const (
+	// OverflowBlock blocks Write until queue has free space or context
+	// passed to Write is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops event being written.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued event.
+	OverflowDropOldest
+	// OverflowDropVerbose drops the oldest queued event with level lower
+	// than LevelImportant. If there is no such event, event being written
+	// is dropped when its level is lower than LevelImportant and Write
+	// blocks like with OverflowBlock otherwise.
+	OverflowDropVerbose
+)
+
+// Async makes logger asynchronous: events passed pre-hooks and level check
+// are put into a bounded queue of given size and then formatted, written to
+// output and passed to post-hooks by a background goroutine, so Write doesn't
+// wait for output. Given policy defines what happens when queue is full.
+//
+// Pre-hooks of asynchronous logger are called concurrently by goroutines
+// calling Write, post-hooks and ErrorHandler are called by the background
+// goroutine. Extra of queued event is copied, so the caller may reuse it
+// after Write returns, nested maps and values are not copied though. Use Flush to wait for queued events to be written and Close
+// to stop the logger.
+func Async(size int, policy OverflowPolicy) Option {
+	return func(l *Logger) {
+		l.queue = newAsyncQueue(size, policy, l.stats)
+		go l.queue.run()
+	}
+}
+
+// Flush blocks until all events queued by asynchronous logger are written
+// or ctx is done. It does nothing for synchronous logger.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.queue == nil {
+		return nil
+	}
+
+	return l.queue.flush(ctx)
+}
+
+// Close stops asynchronous logger: events written after Close are dropped,
+// already queued events are written before Close returns unless ctx is done
+// earlier. Output is not closed. It does nothing for synchronous logger.
+func (l *Logger) Close(ctx context.Context) error {
+	if l.queue == nil {
+		return nil
+	}
+
+	return l.queue.close(ctx)
+}
+
+// queuedEvent is an event waiting to be delivered by logger which wrote it.
+type queuedEvent struct {
+	logger *Logger
+	ctx    context.Context
+	event  Event
+}
+
+func (e *queuedEvent) deliver() {
+	for _, err := range e.logger.deliver(e.ctx, &e.event) {
+		e.logger.errorHandler(e.ctx, e.event, err)
+	}
+}
+
+// asyncQueue is a bounded ring buffer of events shared by asynchronous
+// logger and its children and drained by a single background goroutine.
+type asyncQueue struct {
+	mx sync.Mutex
+
+	items  []queuedEvent
+	head   int
+	size   int
+	policy OverflowPolicy
+	stats  *loggerStats
+
+	delivering bool
+	closed     bool
+
+	wake  chan struct{} // wakes up background goroutine
+	space chan struct{} // closed when event is taken from full queue
+	idle  chan struct{} // closed when queue is drained
+	done  chan struct{} // closed when background goroutine exits
+}
+
+func newAsyncQueue(size int, policy OverflowPolicy, stats *loggerStats) *asyncQueue {
+	if size < 1 {
+		size = 1
+	}
+
+	return &asyncQueue{
+		items:  make([]queuedEvent, size),
+		policy: policy,
+		stats:  stats,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// push puts event into queue applying overflow policy if queue is full.
+func (q *asyncQueue) push(ctx context.Context, item queuedEvent) {
+	var ctxDone <-chan struct{}
+	if ctx != nil {
+		ctxDone = ctx.Done()
+	}
+
+	for {
+		q.mx.Lock()
+
+		if q.closed {
+			q.mx.Unlock()
+			atomic.AddUint64(&q.stats.overflows, 1)
+			return
+		}
+
+		if q.size == len(q.items) && !q.makeRoom(item.event.Level) {
+			if q.policy == OverflowBlock || (q.policy == OverflowDropVerbose && item.event.Level.Gte(LevelImportant)) {
+				if q.space == nil {
+					q.space = make(chan struct{})
+				}
+
+				space := q.space
+				q.mx.Unlock()
+
+				select {
+				case <-space:
+					continue
+				case <-ctxDone:
+					atomic.AddUint64(&q.stats.overflows, 1)
+					return
+				}
+			}
+
+			q.mx.Unlock()
+			atomic.AddUint64(&q.stats.overflows, 1)
+			return
+		}
+
+		q.items[(q.head+q.size)%len(q.items)] = item
+		q.size++
+		q.mx.Unlock()
+
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+
+		return
+	}
+}
+
+// makeRoom drops queued event according to overflow policy and reports
+// whether there is free space in queue now.
+func (q *asyncQueue) makeRoom(level Level) bool {
+	switch q.policy {
+	case OverflowDropOldest:
+		q.remove(0)
+	case OverflowDropVerbose:
+		i := 0
+		for ; i < q.size; i++ {
+			if LevelImportant.Gt(q.items[(q.head+i)%len(q.items)].event.Level) {
+				break
+			}
+		}
+
+		if i == q.size {
+			return false
+		}
+
+		q.remove(i)
+	default:
+		return false
+	}
+
+	atomic.AddUint64(&q.stats.overflows, 1)
+	return true
+}
+
+// remove removes i-th queued event shifting subsequent events.
+func (q *asyncQueue) remove(i int) queuedEvent {
+	n := len(q.items)
+	item := q.items[(q.head+i)%n]
+
+	for j := i; j > 0; j-- {
+		q.items[(q.head+j)%n] = q.items[(q.head+j-1)%n]
+	}
+
+	q.items[q.head] = queuedEvent{}
+	q.head = (q.head + 1) % n
+	q.size--
+
+	return item
+}
+
+// run delivers queued events until queue is closed and drained.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mx.Lock()
+
+		if q.size == 0 {
+			closed := q.closed
+			q.mx.Unlock()
+
+			if closed {
+				return
+			}
+
+			<-q.wake
+			continue
+		}
+
+		item := q.remove(0)
+		q.delivering = true
+
+		if q.space != nil {
+			close(q.space)
+			q.space = nil
+		}
+
+		q.mx.Unlock()
+
+		item.deliver()
+
+		q.mx.Lock()
+		q.delivering = false
+
+		if q.size == 0 && q.idle != nil {
+			close(q.idle)
+			q.idle = nil
+		}
+
+		q.mx.Unlock()
+	}
+}
+
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mx.Lock()
+
+	if q.size == 0 && !q.delivering {
+		q.mx.Unlock()
+		return nil
+	}
+
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+	}
+
+	idle := q.idle
+	q.mx.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mx.Lock()
+
+	q.closed = true
+	if q.space != nil {
+		close(q.space)
+		q.space = nil
+	}
+
+	q.mx.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,298 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+// blockingWriter blocks every write until it's released.
+type blockingWriter struct {
+	mx      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.release
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) lines() []string {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return strings.Fields(w.buf.String())
+}
+
+func newMessageFormatter(t *testing.T) log.Formatter {
+	tmpl, err := template.New("test_async").Parse("{{.Message}}")
+	require.NoError(t, err)
+
+	return log.NewTemplateFormatter(tmpl)
+}
+
+func TestAsync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("events are written in order", func(t *testing.T) {
+		var (
+			mx  sync.Mutex
+			buf bytes.Buffer
+		)
+
+		output := writerFunc(func(p []byte) (int, error) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			return buf.Write(p)
+		})
+
+		logger := log.NewLogger(log.LevelVerbose, output, newMessageFormatter(t), log.Async(4, log.OverflowBlock))
+		for _, msg := range []string{"a", "b", "c", "d", "e", "f"} {
+			logger.Verbose(ctx, msg)
+		}
+
+		require.NoError(t, logger.Flush(ctx))
+
+		mx.Lock()
+		assert.Equal(t, "a\nb\nc\nd\ne\nf\n", buf.String())
+		mx.Unlock()
+
+		require.NoError(t, logger.Close(ctx))
+	})
+
+	policyCases := []struct {
+		name     string
+		policy   log.OverflowPolicy
+		levels   []log.Level
+		expected []string
+		dropped  uint64
+	}{
+		{
+			name:     "drop newest",
+			policy:   log.OverflowDropNewest,
+			levels:   []log.Level{log.LevelVerbose, log.LevelVerbose, log.LevelVerbose},
+			expected: []string{"0", "1", "2"},
+			dropped:  1,
+		},
+		{
+			name:     "drop oldest",
+			policy:   log.OverflowDropOldest,
+			levels:   []log.Level{log.LevelVerbose, log.LevelVerbose, log.LevelVerbose},
+			expected: []string{"0", "2", "3"},
+			dropped:  1,
+		},
+		{
+			name:     "drop verbose queued",
+			policy:   log.OverflowDropVerbose,
+			levels:   []log.Level{log.LevelImportant, log.LevelVerbose, log.LevelImportant},
+			expected: []string{"0", "1", "3"},
+			dropped:  1,
+		},
+		{
+			name:     "drop verbose newest",
+			policy:   log.OverflowDropVerbose,
+			levels:   []log.Level{log.LevelImportant, log.LevelImportant, log.LevelVerbose},
+			expected: []string{"0", "1", "2"},
+			dropped:  1,
+		},
+	}
+
+	for _, tc := range policyCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			output := newBlockingWriter()
+			logger := log.NewLogger(log.LevelVerbose, output, newMessageFormatter(t), log.Async(2, tc.policy))
+
+			// the first event is taken by background goroutine and blocks it
+			logger.Important(ctx, "0")
+			<-output.started
+
+			for i, level := range tc.levels {
+				logger.Write(ctx, level, string(rune('1'+i)), nil)
+			}
+
+			close(output.release)
+			require.NoError(t, logger.Close(ctx))
+
+			assert.Equal(t, tc.expected, output.lines())
+			assert.Equal(t, log.Stats{Overflows: tc.dropped}, logger.Stats())
+		})
+	}
+
+	t.Run("block until context is done", func(t *testing.T) {
+		output := newBlockingWriter()
+		logger := log.NewLogger(log.LevelVerbose, output, newMessageFormatter(t), log.Async(1, log.OverflowBlock))
+
+		logger.Verbose(ctx, "0")
+		<-output.started
+		logger.Verbose(ctx, "1")
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		logger.Verbose(timeoutCtx, "2")
+		assert.Equal(t, uint64(1), logger.Stats().Overflows)
+
+		close(output.release)
+		require.NoError(t, logger.Close(ctx))
+		assert.Equal(t, []string{"0", "1"}, output.lines())
+	})
+
+	t.Run("blocked write is released by consumer", func(t *testing.T) {
+		output := newBlockingWriter()
+		logger := log.NewLogger(log.LevelVerbose, output, newMessageFormatter(t), log.Async(1, log.OverflowBlock))
+
+		logger.Verbose(ctx, "0")
+		<-output.started
+		logger.Verbose(ctx, "1")
+
+		written := make(chan struct{})
+		go func() {
+			logger.Verbose(ctx, "2")
+			close(written)
+		}()
+
+		close(output.release)
+		<-written
+
+		require.NoError(t, logger.Close(ctx))
+		assert.Equal(t, []string{"0", "1", "2"}, output.lines())
+	})
+
+	t.Run("flush and close honor context", func(t *testing.T) {
+		output := newBlockingWriter()
+		logger := log.NewLogger(log.LevelVerbose, output, newMessageFormatter(t), log.Async(1, log.OverflowBlock))
+
+		logger.Verbose(ctx, "0")
+		<-output.started
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, logger.Flush(timeoutCtx))
+		assert.Equal(t, context.DeadlineExceeded, logger.Close(timeoutCtx))
+
+		close(output.release)
+		require.NoError(t, logger.Close(ctx))
+	})
+
+	t.Run("events written after close are dropped", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, &bytes.Buffer{}, newMessageFormatter(t), log.Async(1, log.OverflowBlock))
+		require.NoError(t, logger.Close(ctx))
+
+		logger.Verbose(ctx, "hello")
+		assert.Equal(t, uint64(1), logger.Stats().Overflows)
+	})
+
+	t.Run("child loggers share queue", func(t *testing.T) {
+		var (
+			mx     sync.Mutex
+			events []log.Event
+		)
+
+		logger := log.NewLogger(log.LevelVerbose, &bytes.Buffer{}, newMessageFormatter(t), log.Async(8, log.OverflowBlock))
+		child := logger.With(log.Extra{"child": true})
+		child.PostHook(func(_ context.Context, e *log.Event) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			events = append(events, *e)
+		})
+
+		logger.Verbose(ctx, "parent")
+		child.Verbose(ctx, "child")
+		require.NoError(t, logger.Close(ctx))
+
+		require.Len(t, events, 1)
+		assert.Equal(t, "child", events[0].Message)
+		assert.Equal(t, log.Extra{"child": true}, events[0].Extra)
+	})
+
+	t.Run("sync logger", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, &bytes.Buffer{}, &log.JSONFormatter{})
+
+		assert.NoError(t, logger.Flush(ctx))
+		assert.NoError(t, logger.Close(ctx))
+	})
+}
+
+func TestAsync_Concurrent(t *testing.T) {
+	var (
+		mx    sync.Mutex
+		lines int
+	)
+
+	output := writerFunc(func(p []byte) (int, error) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		lines++
+		return len(p), nil
+	})
+
+	logger := log.NewLogger(log.LevelVerbose, output, &log.JSONFormatter{}, log.Async(16, log.OverflowBlock))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.Verbose(context.Background(), "hello")
+			}
+		}()
+	}
+
+	wg.Wait()
+	require.NoError(t, logger.Close(context.Background()))
+
+	assert.Equal(t, 800, lines)
+}
+
+func TestAsync_ReusedExtra(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := log.NewLogger(log.LevelVerbose, &buf, log.LogfmtFormatter{}, log.Async(16, log.OverflowBlock))
+
+	extra := log.Extra{}
+	for i := 0; i < 100; i++ {
+		extra["n"] = i
+		logger.VerboseX(context.Background(), "hello", extra)
+	}
+
+	require.NoError(t, logger.Close(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 100)
+
+	for i, line := range lines {
+		assert.True(t, strings.HasSuffix(line, " n="+strconv.Itoa(i)), line)
+	}
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Frame describes a function call in goroutine stack.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String returns file and line of the call.
+func (f Frame) String() string {
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// ReportCaller makes logger capture file, line and function which
+// wrote the event and store them in Event.Caller. Caller is captured only
+// for events passing level check, after pre-hooks are called.
+func ReportCaller() Option {
+	return func(l *Logger) {
+		l.reportCaller = true
+	}
+}
+
+// packagePrefix is a prefix of names of functions of this package.
+var packagePrefix = func() string {
+	name := runtime.FuncForPC(reflect.ValueOf(ReportCaller).Pointer()).Name()
+	return name[:strings.LastIndex(name, ".")+1]
+}()
+
+// skipped holds prefixes registered with SkipCallers, it's replaced
+// on every registration.
+var (
+	skippedMx sync.Mutex
+	skipped   atomic.Value
+)
+
+// SkipCallers makes loggers skip functions whose names start with given
+// prefixes, e.g. "github.com/user/adapter.", when capturing caller and
+// stack trace, so adapters and wrappers of Logger are not reported as
+// callers of events they write.
+func SkipCallers(prefixes ...string) {
+	skippedMx.Lock()
+	defer skippedMx.Unlock()
+
+	current, _ := skipped.Load().([]string)
+	skipped.Store(append(current[:len(current):len(current)], prefixes...))
+}
+
+// isSkipped reports whether function belongs to this package or matches
+// prefix registered with SkipCallers.
+func isSkipped(function string) bool {
+	if strings.HasPrefix(function, packagePrefix) {
+		return true
+	}
+
+	prefixes, _ := skipped.Load().([]string)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callerFrame returns the first frame of goroutine stack outside of this
+// package and skipped functions, so both Logger methods and package-level
+// functions report their caller.
+func callerFrame() *Frame {
+	var pcs [32]uintptr
+
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isSkipped(frame.Function) {
+			return &Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			}
+		}
+
+		if !more {
+			return nil
+		}
+	}
+}
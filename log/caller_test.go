@@ -0,0 +1,170 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+func TestReportCaller(t *testing.T) {
+	ctx := context.Background()
+
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}, log.ReportCaller())
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	assertCaller := func(t *testing.T, line int) {
+		t.Helper()
+
+		_, file, _, _ := runtime.Caller(0)
+
+		require.NotNil(t, loggedEvent.Caller)
+		assert.Equal(t, file, loggedEvent.Caller.File)
+		assert.Equal(t, line, loggedEvent.Caller.Line)
+		assert.True(t, strings.HasPrefix(loggedEvent.Caller.Function, "github.com/tomakado/logo/log_test.TestReportCaller"))
+	}
+
+	t.Run("logger methods", func(t *testing.T) {
+		logger.Verbose(ctx, "hello")
+		_, _, line, _ := runtime.Caller(0)
+		assertCaller(t, line-1)
+
+		logger.Importantf(ctx, "hello, %s", "Jon Snow")
+		_, _, line, _ = runtime.Caller(0)
+		assertCaller(t, line-1)
+
+		logger.Write(ctx, log.LevelVerbose, "hello", nil)
+		_, _, line, _ = runtime.Caller(0)
+		assertCaller(t, line-1)
+	})
+
+	t.Run("child logger", func(t *testing.T) {
+		logger.With(log.Extra{"foo": "bar"}).VerboseX(ctx, "hello", nil)
+		_, _, line, _ := runtime.Caller(0)
+		assertCaller(t, line-1)
+	})
+
+	t.Run("package-level functions", func(t *testing.T) {
+		defaultLogger := log.DefaultLogger
+		defer func() {
+			log.DefaultLogger = defaultLogger
+		}()
+
+		log.DefaultLogger = logger
+
+		log.Verbose(ctx, "hello")
+		_, _, line, _ := runtime.Caller(0)
+		assertCaller(t, line-1)
+
+		log.Writef(ctx, log.LevelImportant, "hello, %s", "Jon Snow")
+		_, _, line, _ = runtime.Caller(0)
+		assertCaller(t, line-1)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{})
+		logger.PostHook(func(_ context.Context, e *log.Event) {
+			loggedEvent = e
+		})
+
+		logger.Verbose(ctx, "hello")
+		assert.Nil(t, loggedEvent.Caller)
+	})
+}
+
+// logWrapper stands in for adapter writing events on behalf of its caller.
+func logWrapper(ctx context.Context, logger *log.Logger, msg string) {
+	logger.Verbose(ctx, msg)
+}
+
+func TestSkipCallers(t *testing.T) {
+	log.SkipCallers("github.com/tomakado/logo/log_test.logWrapper")
+
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}, log.ReportCaller(), log.CaptureStack(log.LevelVerbose))
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	logWrapper(context.Background(), logger, "hello")
+	_, file, line, _ := runtime.Caller(0)
+
+	require.NotNil(t, loggedEvent.Caller)
+	assert.Equal(t, file, loggedEvent.Caller.File)
+	assert.Equal(t, line-1, loggedEvent.Caller.Line)
+
+	require.NotEmpty(t, loggedEvent.Stack)
+	assert.Equal(t, line-1, loggedEvent.Stack[0].Line)
+}
+
+func TestReportCaller_LevelCheck(t *testing.T) {
+	ctx := context.Background()
+
+	var preHookCaller, postHookCaller *log.Frame
+
+	logger := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{}, log.ReportCaller())
+	logger.PreHook(func(_ context.Context, e *log.Event) {
+		preHookCaller = e.Caller
+	})
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		postHookCaller = e.Caller
+	})
+
+	logger.Important(ctx, "hello")
+	assert.Nil(t, preHookCaller)
+	assert.NotNil(t, postHookCaller)
+
+	plain := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{})
+
+	dropped := testing.AllocsPerRun(100, func() {
+		logger.Verbose(ctx, "dropped")
+	})
+	droppedPlain := testing.AllocsPerRun(100, func() {
+		plain.Verbose(ctx, "dropped")
+	})
+
+	assert.Equal(t, droppedPlain, dropped, "caller must not be captured for dropped events")
+}
+
+func TestReportCaller_Formatters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger := log.NewLogger(log.LevelVerbose, &buf, &log.JSONFormatter{}, log.ReportCaller())
+		logger.Verbose(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		var decoded struct {
+			Caller log.Frame `json:"caller"`
+		}
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, file, decoded.Caller.File)
+		assert.Equal(t, line-1, decoded.Caller.Line)
+	})
+
+	t.Run("template", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger := log.NewLogger(log.LevelVerbose, &buf, log.SimpleTextFormatter, log.ReportCaller())
+		logger.Verbose(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		assert.Contains(t, buf.String(), " "+file+":"+strconv.Itoa(line-1)+": hello")
+	})
+}
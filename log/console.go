@@ -0,0 +1,148 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used by ConsoleFormatter.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+	ansiYellow = "\x1b[33m"
+)
+
+// consoleTimeLayout is a layout of event time printed by ConsoleFormatter.
+const consoleTimeLayout = "2006-01-02 15:04:05.000"
+
+// ConsoleFormatter is used to output human-friendly logs to terminal:
+//
+//	2021-05-01 12:00:00.000 IMPORTANT payment failed main.go:42
+//	    order_id = 1234
+//	    user.id  = 42
+//
+// Extra keys are sorted and aligned, nested maps are flattened with dotted
+// keys, continuation lines of message and stack trace are indented.
+type ConsoleFormatter struct {
+	// Color enables coloring of levels, timestamps and extra keys.
+	Color bool
+}
+
+// NewConsoleFormatter creates a new instance of ConsoleFormatter with
+// colors enabled if given output is a terminal and NO_COLOR environment
+// variable is not set.
+func NewConsoleFormatter(output io.Writer) *ConsoleFormatter {
+	return &ConsoleFormatter{
+		Color: isTerminal(output) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+	}
+}
+
+// Format renders event as human-friendly text.
+func (f ConsoleFormatter) Format(event Event) (string, error) {
+	if event.Message == nil {
+		return "", nil
+	}
+
+	const indent = "    "
+
+	var builder strings.Builder
+
+	builder.WriteString(f.paint(ansiDim, event.Time.Format(consoleTimeLayout)))
+	builder.WriteByte(' ')
+	builder.WriteString(f.paint(f.levelColor(event.Level), padRight(event.Level.String(), len(LevelImportant.String()))))
+	builder.WriteByte(' ')
+	builder.WriteString(indentLines(TextValue(event.Message), indent))
+
+	if event.Caller != nil {
+		builder.WriteByte(' ')
+		builder.WriteString(f.paint(ansiDim, event.Caller.String()))
+	}
+
+	var keys, values []string
+
+	FlattenExtra(event.Extra, ".", func(key string, value interface{}) {
+		keys = append(keys, key)
+		values = append(values, TextValue(value))
+	})
+
+	var width int
+	for _, key := range keys {
+		if len(key) > width {
+			width = len(key)
+		}
+	}
+
+	valueIndent := indent + strings.Repeat(" ", width+3)
+	for i, key := range keys {
+		builder.WriteString("\n" + indent)
+		builder.WriteString(f.paint(ansiCyan, padRight(key, width)))
+		builder.WriteString(" = ")
+		builder.WriteString(indentLines(values[i], valueIndent))
+	}
+
+	if event.Stack != nil {
+		builder.WriteString("\n")
+		builder.WriteString(f.paint(ansiDim, indentLines(indent+event.Stack.String(), indent)))
+	}
+
+	return builder.String(), nil
+}
+
+// paint wraps s with given color if colors are enabled.
+func (f ConsoleFormatter) paint(color, s string) string {
+	if !f.Color || color == "" {
+		return s
+	}
+
+	return color + s + ansiReset
+}
+
+// levelColor returns color of given level.
+func (f ConsoleFormatter) levelColor(level Level) string {
+	switch {
+	case level.Gt(LevelImportant):
+		return ansiBold + ansiRed
+	case level.Gte(LevelImportant):
+		return ansiRed
+	case level.Gt(LevelVerbose):
+		return ansiYellow
+	case level.Gte(LevelVerbose):
+		return ansiBlue
+	default:
+		return ansiDim
+	}
+}
+
+// indentLines prefixes every line of s except the first one with indent.
+func indentLines(s, indent string) string {
+	return strings.Replace(s, "\n", "\n"+indent, -1)
+}
+
+// padRight pads s with spaces to given width.
+func padRight(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// isTerminal reports whether given writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
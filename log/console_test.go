@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+func TestConsoleFormatter_Format(t *testing.T) {
+	eventTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("empty message", func(t *testing.T) {
+		formatted, err := log.ConsoleFormatter{}.Format(log.NewEvent(log.LevelVerbose, nil, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", formatted)
+	})
+
+	t.Run("without colors", func(t *testing.T) {
+		formatted, err := log.ConsoleFormatter{}.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelVerbose,
+			Message: "payment failed\ncard declined",
+			Extra: log.Extra{
+				"order_id": 1234,
+				"user":     log.Extra{"id": 42},
+				"note":     "first line\nsecond line",
+			},
+			Caller: &log.Frame{Function: "main.main", File: "main.go", Line: 42},
+			Stack:  log.Stack{{Function: "main.main", File: "main.go", Line: 42}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, ""+
+			"2021-05-01 12:00:00.000 VERBOSE   payment failed\n"+
+			"    card declined main.go:42\n"+
+			"    note     = first line\n"+
+			"               second line\n"+
+			"    order_id = 1234\n"+
+			"    user.id  = 42\n"+
+			"    \tmain.main\n"+
+			"    \t\tmain.go:42",
+			formatted,
+		)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		var nilErr *os.PathError
+
+		formatted, err := log.ConsoleFormatter{}.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: nilErr,
+			Extra:   log.Extra{"err": nilErr},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2021-05-01 12:00:00.000 IMPORTANT null\n    err = null", formatted)
+	})
+
+	t.Run("with colors", func(t *testing.T) {
+		formatted, err := log.ConsoleFormatter{Color: true}.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: "hello",
+			Extra:   log.Extra{"foo": "bar"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, ""+
+			"\x1b[2m2021-05-01 12:00:00.000\x1b[0m \x1b[31mIMPORTANT\x1b[0m hello\n"+
+			"    \x1b[36mfoo\x1b[0m = bar",
+			formatted,
+		)
+	})
+}
+
+func TestNewConsoleFormatter(t *testing.T) {
+	t.Run("not a terminal", func(t *testing.T) {
+		assert.False(t, log.NewConsoleFormatter(&bytes.Buffer{}).Color)
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		defer devNull.Close()
+
+		noColor, isSet := os.LookupEnv("NO_COLOR")
+		defer func() {
+			if isSet {
+				os.Setenv("NO_COLOR", noColor)
+			} else {
+				os.Unsetenv("NO_COLOR")
+			}
+		}()
+
+		require.NoError(t, os.Setenv("NO_COLOR", "1"))
+		assert.False(t, log.NewConsoleFormatter(devNull).Color)
+	})
+}
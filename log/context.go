@@ -0,0 +1,55 @@
+package log
+
+import "context"
+
+// extraKey is the key under which Extra is stored in context.Context.
+type extraKey struct{}
+
+// WithExtra returns a copy of ctx carrying given extra. Extra already
+// attached to ctx is preserved, but keys of given extra take precedence
+// over it, so the innermost context wins.
+//
+// Logger merges extra carried by context into every event written
+// with that context. Extra passed at call site beats extra from context.
+func WithExtra(ctx context.Context, extra Extra) context.Context {
+	return context.WithValue(ctx, extraKey{}, mergedExtra(extraFrom(ctx), extra))
+}
+
+// ExtraFrom returns a copy of extra attached to ctx with WithExtra
+// or nil if there is no such extra.
+func ExtraFrom(ctx context.Context) Extra {
+	extra := extraFrom(ctx)
+	if extra == nil {
+		return nil
+	}
+
+	return mergedExtra(extra)
+}
+
+// extraFrom returns extra attached to ctx without copying it.
+func extraFrom(ctx context.Context) Extra {
+	if ctx == nil {
+		return nil
+	}
+
+	extra, _ := ctx.Value(extraKey{}).(Extra)
+	return extra
+}
+
+// mergedExtra returns a new Extra containing keys of all given extras,
+// values of latter extras take precedence.
+func mergedExtra(extras ...Extra) Extra {
+	var size int
+	for _, extra := range extras {
+		size += len(extra)
+	}
+
+	merged := make(Extra, size)
+	for _, extra := range extras {
+		for k, v := range extra {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
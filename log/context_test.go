@@ -0,0 +1,65 @@
+package log_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+func TestWithExtra(t *testing.T) {
+	t.Run("no extra in context", func(t *testing.T) {
+		assert.Nil(t, log.ExtraFrom(context.Background()))
+	})
+
+	t.Run("inner context beats outer", func(t *testing.T) {
+		outer := log.WithExtra(context.Background(), log.Extra{"request_id": "outer", "tenant": "acme"})
+		inner := log.WithExtra(outer, log.Extra{"request_id": "inner", "user_id": 42})
+
+		assert.Equal(t, log.Extra{"request_id": "outer", "tenant": "acme"}, log.ExtraFrom(outer))
+		assert.Equal(t, log.Extra{"request_id": "inner", "tenant": "acme", "user_id": 42}, log.ExtraFrom(inner))
+	})
+
+	t.Run("returned extra is a copy", func(t *testing.T) {
+		ctx := log.WithExtra(context.Background(), log.Extra{"foo": "bar"})
+
+		log.ExtraFrom(ctx)["foo"] = "baz"
+		assert.Equal(t, "bar", log.ExtraFrom(ctx)["foo"])
+	})
+}
+
+func TestLogger_Write_ContextExtra(t *testing.T) {
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{})
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	ctx := log.WithExtra(context.Background(), log.Extra{"request_id": "abc", "user_id": 42})
+
+	t.Run("extra from context", func(t *testing.T) {
+		logger.Verbose(ctx, "hello")
+		assert.Equal(t, log.Extra{"request_id": "abc", "user_id": 42}, loggedEvent.Extra)
+	})
+
+	t.Run("call-site extra beats context extra", func(t *testing.T) {
+		extra := log.Extra{"user_id": 7, "foo": "bar"}
+
+		logger.VerboseX(ctx, "hello", extra)
+		assert.Equal(t, log.Extra{"request_id": "abc", "user_id": 7, "foo": "bar"}, loggedEvent.Extra)
+		assert.Equal(t, log.Extra{"user_id": 7, "foo": "bar"}, extra)
+	})
+
+	t.Run("context extra is not modified by hooks", func(t *testing.T) {
+		logger.PreHook(func(_ context.Context, e *log.Event) {
+			e.Extra["hooked"] = true
+		})
+
+		logger.Verbose(ctx, "hello")
+		assert.Equal(t, true, loggedEvent.Extra["hooked"])
+		assert.NotContains(t, log.ExtraFrom(ctx), "hooked")
+	})
+}
@@ -0,0 +1,137 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorInfo is a structured representation of error used by JSONFormatter.
+type ErrorInfo struct {
+	// Message is a text returned by Error method.
+	Message string `json:"message"`
+	// Type is a Go type name of error, e.g. *fs.PathError.
+	Type string `json:"type"`
+	// Fields holds exported fields of error struct, wrapped errors
+	// are represented by their text.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Chain holds errors wrapped by error, outer errors first.
+	Chain []ErrorInfo `json:"chain,omitempty"`
+}
+
+// NewErrorInfo creates a new instance of ErrorInfo describing given error
+// and all errors it wraps.
+func NewErrorInfo(err error) ErrorInfo {
+	info := newErrorInfo(err)
+
+	// Errors aren't compared as their dynamic types may be uncomparable,
+	// the first visited error is err itself.
+	first := true
+
+	walkErrors(err, func(wrapped error) {
+		if first {
+			first = false
+			return
+		}
+
+		info.Chain = append(info.Chain, newErrorInfo(wrapped))
+	})
+
+	return info
+}
+
+// newErrorInfo describes given error without errors it wraps.
+func newErrorInfo(err error) ErrorInfo {
+	return ErrorInfo{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+		Fields:  errorFields(err),
+	}
+}
+
+// errorFields returns exported fields of error struct honoring json tags.
+func errorFields(err error) map[string]interface{} {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields map[string]interface{}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+
+		value := v.Field(i).Interface()
+		if wrapped, ok := value.(error); ok {
+			if isNil(v.Field(i)) {
+				value = nil
+			} else {
+				value = wrapped.Error()
+			}
+		}
+
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+
+		fields[name] = value
+	}
+
+	return fields
+}
+
+// isNil reports whether value is nil pointer or interface.
+func isNil(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+
+		v = v.Elem()
+	}
+
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// jsonValue replaces errors which cannot be marshaled to JSON on their
+// own with ErrorInfo and typed nil errors with nil.
+func jsonValue(v interface{}) (interface{}, bool) {
+	err, ok := v.(error)
+	if !ok {
+		return v, false
+	}
+
+	if isNil(reflect.ValueOf(err)) {
+		return nil, true
+	}
+
+	if _, ok := err.(json.Marshaler); ok {
+		return v, false
+	}
+
+	return NewErrorInfo(err), true
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// ErrorHandler is a function being called when logger fails to format
+// event or to write it to output. It's called once per failed sink and
+// outside of logger's lock, so it's safe to log from it.
+type ErrorHandler func(ctx context.Context, e Event, err error)
+
+// Built-in error handlers.
+var (
+	// PanicOnError panics with given error. It's used by default.
+	PanicOnError ErrorHandler = func(_ context.Context, _ Event, err error) {
+		panic(err)
+	}
+
+	// DropOnError silently drops event.
+	DropOnError ErrorHandler = func(context.Context, Event, error) {}
+)
+
+// FallbackOnError returns ErrorHandler reporting error and dropped event
+// to given writer, e.g. os.Stderr. Errors of fallback writer are ignored.
+func FallbackOnError(w io.Writer) ErrorHandler {
+	return func(_ context.Context, e Event, err error) {
+		_, _ = fmt.Fprintf(w, "logo: %v; dropped event: %s @ %s: %v\n", err, e.Level, e.Time, e.Message)
+	}
+}
+
+// OnError sets handler to be called when logger fails to format or write event.
+func OnError(h ErrorHandler) Option {
+	return func(l *Logger) {
+		l.errorHandler = h
+	}
+}
+
+// Stats holds counters of events dropped by logger. Event failed to be
+// written to several sinks is counted once per sink.
+type Stats struct {
+	// FormatErrors is number of events dropped because formatter failed.
+	FormatErrors uint64
+	// WriteErrors is number of events dropped because output failed.
+	WriteErrors uint64
+	// Overflows is number of events dropped by asynchronous logger
+	// because its queue was full or logger was closed.
+	Overflows uint64
+}
+
+// Dropped returns total number of dropped events.
+func (s Stats) Dropped() uint64 {
+	return s.FormatErrors + s.WriteErrors + s.Overflows
+}
+
+// loggerStats holds counters updated atomically by logger.
+type loggerStats struct {
+	formatErrors uint64
+	writeErrors  uint64
+	overflows    uint64
+}
+
+func (s *loggerStats) snapshot() Stats {
+	return Stats{
+		FormatErrors: atomic.LoadUint64(&s.formatErrors),
+		WriteErrors:  atomic.LoadUint64(&s.writeErrors),
+		Overflows:    atomic.LoadUint64(&s.overflows),
+	}
+}
@@ -0,0 +1,112 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+func TestOnError(t *testing.T) {
+	t.Run("drop", func(t *testing.T) {
+		var postHookCalled bool
+
+		logger := log.NewLogger(log.LevelVerbose, errorWriter{}, &log.JSONFormatter{}, log.OnError(log.DropOnError))
+		logger.PostHook(func(_ context.Context, _ *log.Event) {
+			postHookCalled = true
+		})
+
+		assert.NotPanics(t, func() {
+			logger.Verbose(context.Background(), "hello")
+		})
+		assert.False(t, postHookCalled)
+		assert.Equal(t, log.Stats{WriteErrors: 1}, logger.Stats())
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		var fallback bytes.Buffer
+
+		logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, errorFormatter{}, log.OnError(log.FallbackOnError(&fallback)))
+		logger.Important(context.Background(), "hello")
+
+		assert.Contains(t, fallback.String(), "format event: error!")
+		assert.Contains(t, fallback.String(), "IMPORTANT")
+		assert.Contains(t, fallback.String(), "hello")
+		assert.Equal(t, log.Stats{FormatErrors: 1}, logger.Stats())
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		var (
+			handledEvent log.Event
+			handledErr   error
+		)
+
+		logger := log.NewLogger(
+			log.LevelVerbose,
+			errorWriter{},
+			&log.JSONFormatter{},
+			log.OnError(func(_ context.Context, e log.Event, err error) {
+				handledEvent = e
+				handledErr = err
+			}),
+		)
+
+		logger.VerboseX(context.Background(), "hello", log.Extra{"foo": "bar"})
+
+		assert.Equal(t, "hello", handledEvent.Message)
+		assert.Equal(t, log.Extra{"foo": "bar"}, handledEvent.Extra)
+		assert.EqualError(t, handledErr, "write event: error!")
+	})
+
+	t.Run("handler may log with same logger", func(t *testing.T) {
+		var logger *log.Logger
+
+		logger = log.NewLogger(
+			log.LevelVerbose,
+			errorWriter{},
+			&log.JSONFormatter{},
+			log.OnError(func(ctx context.Context, e log.Event, err error) {
+				if e.Level == log.LevelVerbose {
+					logger.Important(ctx, err)
+				}
+			}),
+		)
+
+		logger.Verbose(context.Background(), "hello")
+		assert.Equal(t, uint64(2), logger.Stats().Dropped())
+	})
+
+	t.Run("child shares stats", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, errorWriter{}, &log.JSONFormatter{}, log.OnError(log.DropOnError))
+		logger.With(log.Extra{"foo": "bar"}).Verbose(context.Background(), "hello")
+
+		assert.Equal(t, uint64(1), logger.Stats().Dropped())
+	})
+
+	t.Run("wrapped error", func(t *testing.T) {
+		errDiskFull := errors.New("disk full")
+
+		var handledErr error
+		logger := log.NewLogger(
+			log.LevelVerbose,
+			writerFunc(func([]byte) (int, error) { return 0, errDiskFull }),
+			&log.JSONFormatter{},
+			log.OnError(func(_ context.Context, _ log.Event, err error) {
+				handledErr = err
+			}),
+		)
+
+		logger.Verbose(context.Background(), "hello")
+		assert.True(t, errors.Is(handledErr, errDiskFull))
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
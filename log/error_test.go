@@ -0,0 +1,173 @@
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+type codeError struct {
+	Code   int    `json:"code"`
+	Secret string `json:"-"`
+	Reason string
+	cause  error
+}
+
+func (e *codeError) Error() string { return fmt.Sprintf("code %d: %s", e.Code, e.Reason) }
+func (e *codeError) Unwrap() error { return e.cause }
+
+type marshalerError struct{}
+
+func (marshalerError) Error() string                { return "marshaler error" }
+func (marshalerError) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+type validationError struct {
+	Fields []string
+	cause  error
+}
+
+func (e validationError) Error() string { return "invalid fields: " + strings.Join(e.Fields, ", ") }
+func (e validationError) Unwrap() error { return e.cause }
+
+type outerError struct {
+	Inner *codeError
+}
+
+func (e *outerError) Error() string { return "outer" }
+
+func TestNewErrorInfo(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "/nope", Err: os.ErrNotExist}
+	err := fmt.Errorf("load config: %w", &codeError{Code: 42, Secret: "s3cr3t", Reason: "io", cause: pathErr})
+
+	assert.Equal(t, log.ErrorInfo{
+		Message: "load config: code 42: io",
+		Type:    "*fmt.wrapError",
+		Chain: []log.ErrorInfo{
+			{
+				Message: "code 42: io",
+				Type:    "*log_test.codeError",
+				Fields:  map[string]interface{}{"code": 42, "Reason": "io"},
+			},
+			{
+				Message: "open /nope: file does not exist",
+				Type:    fmt.Sprintf("%T", pathErr),
+				Fields:  map[string]interface{}{"Op": "open", "Path": "/nope", "Err": "file does not exist"},
+			},
+			{
+				Message: "file does not exist",
+				Type:    fmt.Sprintf("%T", os.ErrNotExist),
+			},
+		},
+	}, log.NewErrorInfo(err))
+
+	t.Run("uncomparable error", func(t *testing.T) {
+		err := validationError{Fields: []string{"name"}, cause: validationError{Fields: []string{"age"}}}
+
+		assert.Equal(t, log.ErrorInfo{
+			Message: "invalid fields: name",
+			Type:    "log_test.validationError",
+			Fields:  map[string]interface{}{"Fields": []string{"name"}},
+			Chain: []log.ErrorInfo{
+				{
+					Message: "invalid fields: age",
+					Type:    "log_test.validationError",
+					Fields:  map[string]interface{}{"Fields": []string{"age"}},
+				},
+			},
+		}, log.NewErrorInfo(err))
+	})
+
+	t.Run("nil error field", func(t *testing.T) {
+		assert.Equal(t, log.ErrorInfo{
+			Message: "outer",
+			Type:    "*log_test.outerError",
+			Fields:  map[string]interface{}{"Inner": nil},
+		}, log.NewErrorInfo(&outerError{}))
+	})
+}
+
+func TestJSONFormatter_Format_Errors(t *testing.T) {
+	formatter := &log.JSONFormatter{}
+
+	t.Run("error message", func(t *testing.T) {
+		event := log.NewEvent(log.LevelImportant, errors.New("oops"), nil)
+
+		formatted, err := formatter.Format(event)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(formatted), &decoded))
+		assert.Equal(t, map[string]interface{}{
+			"message": "oops",
+			"type":    "*errors.errorString",
+		}, decoded["message"])
+	})
+
+	t.Run("error in extra", func(t *testing.T) {
+		extra := log.Extra{"err": &codeError{Code: 1, Reason: "db"}, "foo": "bar"}
+		event := log.NewEvent(log.LevelImportant, "request failed", extra)
+
+		formatted, err := formatter.Format(event)
+		require.NoError(t, err)
+
+		var decoded struct {
+			Extra map[string]interface{} `json:"extra"`
+		}
+
+		require.NoError(t, json.Unmarshal([]byte(formatted), &decoded))
+		assert.Equal(t, map[string]interface{}{
+			"err": map[string]interface{}{
+				"message": "code 1: db",
+				"type":    "*log_test.codeError",
+				"fields":  map[string]interface{}{"code": float64(1), "Reason": "db"},
+			},
+			"foo": "bar",
+		}, decoded.Extra)
+
+		_, isErr := extra["err"].(error)
+		assert.True(t, isErr, "original extra must not be modified")
+	})
+
+	t.Run("uncomparable error in extra", func(t *testing.T) {
+		event := log.NewEvent(log.LevelImportant, "x", log.Extra{"err": validationError{Fields: []string{"name"}}})
+
+		formatted, err := formatter.Format(event)
+		require.NoError(t, err)
+		assert.Contains(t, formatted, `"message":"invalid fields: name"`)
+	})
+
+	t.Run("nil error field", func(t *testing.T) {
+		event := log.NewEvent(log.LevelImportant, &outerError{}, nil)
+
+		formatted, err := formatter.Format(event)
+		require.NoError(t, err)
+		assert.Contains(t, formatted, `"fields":{"Inner":null}`)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		var nilErr *codeError
+
+		for _, formatter := range []*log.JSONFormatter{{}, {KeyOrder: []string{"message"}}} {
+			formatted, err := formatter.Format(log.NewEvent(log.LevelImportant, nilErr, log.Extra{"err": nilErr}))
+			require.NoError(t, err)
+
+			assert.Contains(t, formatted, `"message":null`)
+			assert.Contains(t, formatted, `"extra":{"err":null}`)
+		}
+	})
+
+	t.Run("error implementing json.Marshaler", func(t *testing.T) {
+		event := log.NewEvent(log.LevelImportant, marshalerError{}, nil)
+
+		formatted, err := formatter.Format(event)
+		require.NoError(t, err)
+		assert.Contains(t, formatted, `"message":"custom"`)
+	})
+}
@@ -9,6 +9,8 @@ type Event struct {
 	Level   Level       `json:"-"`
 	Message interface{} `json:"message"`
 	Extra   Extra       `json:"extra,omitempty"`
+	Caller  *Frame      `json:"caller,omitempty"`
+	Stack   Stack       `json:"stack,omitempty"`
 }
 
 // Extra is a set of key-value pairs (map in other words) used to
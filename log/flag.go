@@ -0,0 +1,77 @@
+package log
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// LevelFlag is a flag.Value setting level by name or numeric value of
+// registered level. It also has Type method required by pflag.Value.
+type LevelFlag struct {
+	level *Level
+}
+
+// NewLevelFlag creates a new instance of LevelFlag storing level in p
+// and sets p to given default value.
+func NewLevelFlag(p *Level, value Level) *LevelFlag {
+	*p = value
+
+	return &LevelFlag{
+		level: p,
+	}
+}
+
+// String returns name of current level.
+func (f *LevelFlag) String() string {
+	if f == nil || f.level == nil {
+		return ""
+	}
+
+	return f.level.String()
+}
+
+// Set parses level with ParseLevel.
+func (f *LevelFlag) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return fmt.Errorf("%w, allowed levels: %s", err, levelNames())
+	}
+
+	*f.level = level
+
+	return nil
+}
+
+// Type returns name of flag value type.
+func (f *LevelFlag) Type() string {
+	return "level"
+}
+
+// Get returns current level, it implements flag.Getter.
+func (f *LevelFlag) Get() interface{} {
+	return *f.level
+}
+
+// LevelVar defines a level flag with given name, default value and usage
+// in given flag set or in flag.CommandLine if it's nil. Names of registered
+// levels are appended to usage.
+func LevelVar(fs *flag.FlagSet, p *Level, name string, value Level, usage string) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	fs.Var(NewLevelFlag(p, value), name, fmt.Sprintf("%s (one of %s)", usage, levelNames()))
+}
+
+// levelNames returns comma separated names of registered levels.
+func levelNames() string {
+	levels := Levels()
+
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = level.String()
+	}
+
+	return strings.Join(names, ", ")
+}
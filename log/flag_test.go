@@ -0,0 +1,71 @@
+package log_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+func TestLevelVar(t *testing.T) {
+	newFlagSet := func() (*flag.FlagSet, *log.Level) {
+		var level log.Level
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		log.LevelVar(fs, &level, "log-level", log.LevelImportant, "logging level")
+
+		return fs, &level
+	}
+
+	t.Run("default value", func(t *testing.T) {
+		fs, level := newFlagSet()
+
+		assert.NoError(t, fs.Parse(nil))
+		assert.Equal(t, log.LevelImportant, *level)
+	})
+
+	t.Run("name", func(t *testing.T) {
+		fs, level := newFlagSet()
+
+		assert.NoError(t, fs.Parse([]string{"-log-level", "verbose"}))
+		assert.Equal(t, log.LevelVerbose, *level)
+		assert.Equal(t, log.LevelVerbose, fs.Lookup("log-level").Value.(flag.Getter).Get())
+	})
+
+	t.Run("numeric value", func(t *testing.T) {
+		fs, level := newFlagSet()
+
+		assert.NoError(t, fs.Parse([]string{"-log-level=10"}))
+		assert.Equal(t, log.LevelVerbose, *level)
+	})
+
+	t.Run("unknown level", func(t *testing.T) {
+		fs, level := newFlagSet()
+
+		err := fs.Parse([]string{"-log-level", "debug"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "allowed levels: VERBOSE, IMPORTANT")
+		assert.Equal(t, log.LevelImportant, *level)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		fs, _ := newFlagSet()
+
+		var usage bytes.Buffer
+		fs.SetOutput(&usage)
+		fs.PrintDefaults()
+
+		assert.Contains(t, usage.String(), "logging level (one of VERBOSE, IMPORTANT")
+		assert.Contains(t, usage.String(), "(default IMPORTANT)")
+	})
+}
+
+func TestLevelFlag_Type(t *testing.T) {
+	var level log.Level
+
+	assert.Equal(t, "level", log.NewLevelFlag(&level, log.LevelVerbose).Type())
+}
@@ -1,9 +1,11 @@
 package log
 
 import (
-	"encoding/json"
+	"io"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/tomakado/logo/utils"
 )
@@ -19,29 +21,420 @@ type Formatter interface {
 	Format(event Event) (string, error)
 }
 
+// AppendFormatter converts given event to bytes appending them to dst.
+// Logger prefers AppendFormat over Format if formatter implements both
+// interfaces, so events are written without intermediate strings.
+type AppendFormatter interface {
+	AppendFormat(dst []byte, event Event) ([]byte, error)
+}
+
+// AsAppendFormatter returns given formatter as AppendFormatter.
+// Formatters without AppendFormat method are wrapped to append
+// the string returned by Format.
+func AsAppendFormatter(formatter Formatter) AppendFormatter {
+	if af, ok := formatter.(AppendFormatter); ok {
+		return af
+	}
+
+	return stringFormatter{formatter}
+}
+
+// AsFormatter returns Formatter which converts events to string with
+// given AppendFormatter, so it can be passed to NewLogger and Sink.
+// Logger still uses AppendFormat of the returned formatter.
+func AsFormatter(formatter AppendFormatter) Formatter {
+	if f, ok := formatter.(Formatter); ok {
+		return f
+	}
+
+	return appendFormatter{formatter}
+}
+
+// stringFormatter adapts Formatter to AppendFormatter.
+type stringFormatter struct {
+	Formatter
+}
+
+// AppendFormat appends formatted event to dst.
+func (f stringFormatter) AppendFormat(dst []byte, event Event) ([]byte, error) {
+	formatted, err := f.Format(event)
+	if err != nil {
+		return dst, err
+	}
+
+	return append(dst, formatted...), nil
+}
+
+// appendFormatter adapts AppendFormatter to Formatter.
+type appendFormatter struct {
+	AppendFormatter
+}
+
+// Format converts given event to string.
+func (f appendFormatter) Format(event Event) (string, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var err error
+
+	*buf, err = f.AppendFormat(*buf, event)
+	if err != nil {
+		return "", err
+	}
+
+	return string(*buf), nil
+}
+
 // JSONFormatter is used to output logs as JSON string.
-type JSONFormatter struct{}
+// Errors passed as message or extra values are rendered as ErrorInfo.
+//
+// Zero value renders level, time, message, extra, caller and stack
+// under keys "level", "time", "message", "extra", "caller" and "stack"
+// in this order, fields of JSONFormatter override this layout.
+type JSONFormatter struct {
+	// LevelKey, TimeKey, MessageKey, ExtraKey, CallerKey and StackKey
+	// override names of corresponding keys.
+	LevelKey   string
+	TimeKey    string
+	MessageKey string
+	ExtraKey   string
+	CallerKey  string
+	StackKey   string
+
+	// FlattenExtra puts extra fields at the top level of JSON object
+	// in order of keys instead of nesting them under extra key. Extra
+	// fields clashing with other keys are prefixed with extra key and dot.
+	FlattenExtra bool
+
+	// TimeFormat converts event time to JSON value, RFC 3339 string
+	// with nanoseconds is used by default.
+	TimeFormat TimeFormat
+
+	// KeyOrder lists keys (including flattened extra keys) which must go
+	// first in given order, other keys follow them in default order.
+	KeyOrder []string
+}
+
+// TimeFormat converts event time to JSON value.
+type TimeFormat func(t time.Time) interface{}
+
+// Supported time formats.
+var (
+	TimeRFC3339Nano = TimeLayout(time.RFC3339Nano)
+	TimeRFC3339     = TimeLayout(time.RFC3339)
+
+	TimeUnix TimeFormat = func(t time.Time) interface{} {
+		return t.Unix()
+	}
+	TimeUnixMilli TimeFormat = func(t time.Time) interface{} {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	TimeUnixNano TimeFormat = func(t time.Time) interface{} {
+		return t.UnixNano()
+	}
+)
+
+// TimeLayout returns TimeFormat converting time to string with given layout.
+func TimeLayout(layout string) TimeFormat {
+	return func(t time.Time) interface{} {
+		return t.Format(layout)
+	}
+}
+
+// jsonField is a key-value pair of JSON object.
+type jsonField struct {
+	key   string
+	value interface{}
+}
 
 // Format converts given event to JSON string.
 func (f JSONFormatter) Format(event Event) (string, error) {
-	if event.Message == nil {
-		return "", nil
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var err error
+
+	*buf, err = f.AppendFormat(*buf, event)
+	if err != nil {
+		return "", err
 	}
 
-	jsonEvent := struct {
-		Level string `json:"level"`
-		*Event
-	}{
-		Level: event.Level.String(),
-		Event: &event,
+	return string(*buf), nil
+}
+
+// Encode writes JSON representation of given event followed by newline
+// to w. Events without message are skipped.
+func (f JSONFormatter) Encode(w io.Writer, event Event) error {
+	if event.Message == nil {
+		return nil
 	}
 
-	bytes, err := json.Marshal(jsonEvent)
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var err error
+
+	*buf, err = f.AppendFormat(*buf, event)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	*buf = append(*buf, '\n')
+	_, err = w.Write(*buf)
+
+	return err
+}
+
+// AppendFormat appends JSON representation of given event to dst and
+// returns the extended buffer. Output is the same as of Format, but common
+// value types are encoded without allocations unless KeyOrder is set.
+func (f JSONFormatter) AppendFormat(dst []byte, event Event) ([]byte, error) {
+	if event.Message == nil {
+		return dst, nil
+	}
+
+	e := jsonEncoder{buf: dst}
+
+	if len(f.KeyOrder) > 0 {
+		e.buf = append(e.buf, '{')
+
+		for i, field := range f.fields(event) {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+
+			e.key(field.key)
+			e.value(field.value)
+		}
+
+		e.buf = append(e.buf, '}')
+
+		return e.result(len(dst))
+	}
+
+	var (
+		levelKey   = keyOrDefault(f.LevelKey, "level")
+		timeKey    = keyOrDefault(f.TimeKey, "time")
+		messageKey = keyOrDefault(f.MessageKey, "message")
+		extraKey   = keyOrDefault(f.ExtraKey, "extra")
+		callerKey  = keyOrDefault(f.CallerKey, "caller")
+		stackKey   = keyOrDefault(f.StackKey, "stack")
+	)
+
+	e.buf = append(e.buf, '{')
+	e.key(levelKey)
+	e.string(event.Level.String())
+
+	e.buf = append(e.buf, ',')
+	e.key(timeKey)
+
+	if f.TimeFormat == nil {
+		e.time(event.Time)
+	} else {
+		e.value(f.TimeFormat(event.Time))
+	}
+
+	e.buf = append(e.buf, ',')
+	e.key(messageKey)
+	e.field(event.Message)
+
+	if !f.FlattenExtra && len(event.Extra) > 0 {
+		e.buf = append(e.buf, ',')
+		e.key(extraKey)
+		e.object(event.Extra, true)
+	}
+
+	if event.Caller != nil {
+		e.buf = append(e.buf, ',')
+		e.key(callerKey)
+		e.frame(*event.Caller)
+	}
+
+	if len(event.Stack) > 0 {
+		e.buf = append(e.buf, ',')
+		e.key(stackKey)
+		e.stack(event.Stack)
+	}
+
+	if f.FlattenExtra && len(event.Extra) > 0 {
+		reserved := func(k string) bool {
+			return k == levelKey || k == timeKey || k == messageKey ||
+				k == callerKey && event.Caller != nil ||
+				k == stackKey && len(event.Stack) > 0
+		}
+
+		var renamed []string
+
+		keys := sortedKeys(event.Extra)
+
+		for _, k := range *keys {
+			e.buf = append(e.buf, ',')
+
+			if reserved(k) {
+				key := renamedExtraKey(k, extraKey, event.Extra, func(key string) bool {
+					return reserved(key) || containsString(renamed, key)
+				})
+				renamed = append(renamed, key)
+
+				e.key(key)
+			} else {
+				e.key(k)
+			}
+
+			e.field(event.Extra[k])
+		}
+
+		putKeys(keys)
+	}
+
+	e.buf = append(e.buf, '}')
+
+	return e.result(len(dst))
+}
+
+// fields returns key-value pairs of JSON object representing event
+// in order they must be rendered.
+func (f JSONFormatter) fields(event Event) []jsonField {
+	extraKey := keyOrDefault(f.ExtraKey, "extra")
+
+	fields := make([]jsonField, 0, 6)
+	fields = append(fields,
+		jsonField{key: keyOrDefault(f.LevelKey, "level"), value: event.Level.String()},
+		jsonField{key: keyOrDefault(f.TimeKey, "time"), value: f.timeValue(event.Time)},
+	)
+
+	message, _ := jsonValue(event.Message)
+	fields = append(fields, jsonField{key: keyOrDefault(f.MessageKey, "message"), value: message})
+
+	if !f.FlattenExtra && len(event.Extra) > 0 {
+		fields = append(fields, jsonField{key: extraKey, value: jsonExtra(event.Extra)})
+	}
+
+	if event.Caller != nil {
+		fields = append(fields, jsonField{key: keyOrDefault(f.CallerKey, "caller"), value: event.Caller})
+	}
+
+	if len(event.Stack) > 0 {
+		fields = append(fields, jsonField{key: keyOrDefault(f.StackKey, "stack"), value: event.Stack})
+	}
+
+	if f.FlattenExtra {
+		fields = appendFlatExtra(fields, extraKey, event.Extra)
+	}
+
+	if len(f.KeyOrder) > 0 {
+		rank := make(map[string]int, len(f.KeyOrder))
+		for i, key := range f.KeyOrder {
+			rank[key] = i - len(f.KeyOrder)
+		}
+
+		sort.SliceStable(fields, func(i, j int) bool {
+			return rank[fields[i].key] < rank[fields[j].key]
+		})
+	}
+
+	return fields
+}
+
+// timeValue converts event time according to time format.
+func (f JSONFormatter) timeValue(t time.Time) interface{} {
+	if f.TimeFormat == nil {
+		return t
+	}
+
+	return f.TimeFormat(t)
+}
+
+// appendFlatExtra appends extra fields sorted by key to given fields,
+// keys clashing with existing ones are renamed with renamedExtraKey.
+func appendFlatExtra(fields []jsonField, extraKey string, extra Extra) []jsonField {
+	reserved := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		reserved[field.key] = true
+	}
+
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value, _ := jsonValue(extra[k])
+
+		key := k
+		if reserved[key] {
+			key = renamedExtraKey(k, extraKey, extra, func(key string) bool {
+				return reserved[key]
+			})
+			reserved[key] = true
+		}
+
+		fields = append(fields, jsonField{key: key, value: value})
+	}
+
+	return fields
+}
+
+// renamedExtraKey returns key of flattened extra field clashing with
+// event field. Key is prefixed with extra key until it clashes neither
+// with other extra keys nor with keys taken according to taken.
+func renamedExtraKey(k, extraKey string, extra Extra, taken func(key string) bool) string {
+	key := extraKey + "." + k
+
+	for {
+		if _, ok := extra[key]; !ok && !taken(key) {
+			return key
+		}
+
+		key = extraKey + "." + key
+	}
+}
+
+// containsString reports whether s is among given strings.
+func containsString(ss []string, s string) bool {
+	for _, candidate := range ss {
+		if candidate == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keyOrDefault returns given key or default one if key is empty.
+func keyOrDefault(key, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+
+	return key
+}
+
+// jsonExtra returns copy of extra with errors replaced with ErrorInfo
+// or extra itself if it contains no errors.
+func jsonExtra(extra Extra) Extra {
+	var replaced Extra
+
+	for k, v := range extra {
+		value, ok := jsonValue(v)
+		if !ok {
+			continue
+		}
+
+		if replaced == nil {
+			replaced = mergedExtra(extra)
+		}
+
+		replaced[k] = value
+	}
+
+	if replaced == nil {
+		return extra
 	}
 
-	return string(bytes), nil
+	return replaced
 }
 
 // TemplateFormatter is used to output logs rendered with template.
@@ -72,7 +465,7 @@ func (f TemplateFormatter) Format(event Event) (string, error) {
 
 // createSimpleTextFormatter create TemplateFormatter with simple text layout.
 func createSimpleTextFormatter() {
-	tmpl, err := template.New("simplefmt").Parse("{{.Level}} @ {{.Time}}: {{.Message}}{{if .Extra}}; {{.Extra}}{{end}}")
+	tmpl, err := template.New("simplefmt").Parse("{{.Level}} @ {{.Time}}{{with .Caller}} {{.}}{{end}}: {{.Message}}{{if .Extra}}; {{.Extra}}{{end}}{{with .Stack}}\n{{.}}{{end}}")
 	if err != nil {
 		panic(err)
 	}
@@ -87,7 +480,7 @@ func createTableTextFormatter() {
 			"fmtTime":  utils.FormatTimeRuby,
 			"fmtLevel": utils.FormatLevelFixedWidth,
 		}).
-		Parse("| {{.Level.String|fmtLevel}} | {{.Time|fmtTime}} | {{.Message}}{{if .Extra}}; extra: {{.Extra}} {{end}}")
+		Parse("| {{.Level.String|fmtLevel}} | {{.Time|fmtTime}} | {{with .Caller}}{{.}} | {{end}}{{.Message}}{{if .Extra}}; extra: {{.Extra}} {{end}}{{with .Stack}}\n{{.}}{{end}}")
 	if err != nil {
 		panic(err)
 	}
@@ -2,6 +2,8 @@ package log_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"text/template"
@@ -49,6 +51,198 @@ func TestJSONFormatter_Format(t *testing.T) {
 	})
 }
 
+func TestJSONFormatter_Options(t *testing.T) {
+	eventTime := time.Date(2021, 5, 17, 10, 30, 0, 123000000, time.UTC)
+	event := log.Event{
+		Time:    eventTime,
+		Level:   log.LevelImportant,
+		Message: "hello",
+		Extra:   map[string]interface{}{"zeta": 1, "alpha": "a", "msg": "clash"},
+	}
+
+	t.Run("field names", func(t *testing.T) {
+		formatter := log.JSONFormatter{
+			LevelKey:   "lvl",
+			TimeKey:    "ts",
+			MessageKey: "msg",
+			ExtraKey:   "fields",
+		}
+
+		formattedEvent, err := formatter.Format(event)
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			`{"lvl":"IMPORTANT","ts":"2021-05-17T10:30:00.123Z","msg":"hello","fields":{"alpha":"a","msg":"clash","zeta":1}}`,
+			formattedEvent,
+		)
+	})
+
+	t.Run("flatten extra", func(t *testing.T) {
+		formatter := log.JSONFormatter{MessageKey: "msg", FlattenExtra: true}
+
+		formattedEvent, err := formatter.Format(event)
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			`{"level":"IMPORTANT","time":"2021-05-17T10:30:00.123Z","msg":"hello","alpha":"a","extra.msg":"clash","zeta":1}`,
+			formattedEvent,
+		)
+	})
+
+	t.Run("flatten extra with renamed key taken", func(t *testing.T) {
+		event := log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: "hello",
+			Extra:   log.Extra{"msg": 1, "extra.msg": 2, "extra.extra.msg": 3, "level": 4},
+		}
+
+		expected := `{"level":"IMPORTANT","time":"2021-05-17T10:30:00.123Z","msg":"hello",` +
+			`"extra.extra.msg":3,"extra.msg":2,"extra.level":4,"extra.extra.extra.msg":1}`
+
+		for _, formatter := range []log.JSONFormatter{
+			{MessageKey: "msg", FlattenExtra: true},
+			{MessageKey: "msg", FlattenExtra: true, KeyOrder: []string{"level"}},
+		} {
+			formattedEvent, err := formatter.Format(event)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, formattedEvent)
+		}
+
+		t.Run("renamed keys", func(t *testing.T) {
+			formatter := log.JSONFormatter{LevelKey: "extra.msg", MessageKey: "msg", FlattenExtra: true}
+
+			formattedEvent, err := formatter.Format(log.Event{
+				Time:    eventTime,
+				Level:   log.LevelImportant,
+				Message: "hello",
+				Extra:   log.Extra{"msg": 1, "extra.msg": 2},
+			})
+			assert.NoError(t, err)
+			assert.Equal(
+				t,
+				`{"extra.msg":"IMPORTANT","time":"2021-05-17T10:30:00.123Z","msg":"hello","extra.extra.msg":2,"extra.extra.extra.msg":1}`,
+				formattedEvent,
+			)
+		})
+	})
+
+	t.Run("time format", func(t *testing.T) {
+		cases := []struct {
+			format   log.TimeFormat
+			expected string
+		}{
+			{log.TimeRFC3339, `"2021-05-17T10:30:00Z"`},
+			{log.TimeRFC3339Nano, `"2021-05-17T10:30:00.123Z"`},
+			{log.TimeUnix, `1621247400`},
+			{log.TimeUnixMilli, `1621247400123`},
+			{log.TimeUnixNano, `1621247400123000000`},
+			{log.TimeLayout("15:04"), `"10:30"`},
+		}
+
+		for _, c := range cases {
+			formatter := log.JSONFormatter{TimeFormat: c.format}
+
+			formattedEvent, err := formatter.Format(log.Event{Time: eventTime, Level: log.LevelVerbose, Message: "hi"})
+			assert.NoError(t, err)
+			assert.Equal(t, `{"level":"VERBOSE","time":`+c.expected+`,"message":"hi"}`, formattedEvent)
+		}
+	})
+
+	t.Run("key order", func(t *testing.T) {
+		formatter := log.JSONFormatter{
+			FlattenExtra: true,
+			KeyOrder:     []string{"message", "zeta", "level"},
+		}
+
+		formattedEvent, err := formatter.Format(event)
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			`{"message":"hello","zeta":1,"level":"IMPORTANT","time":"2021-05-17T10:30:00.123Z","alpha":"a","msg":"clash"}`,
+			formattedEvent,
+		)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		formatter := log.JSONFormatter{FlattenExtra: true}
+
+		first, err := formatter.Format(event)
+		assert.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			formattedEvent, err := formatter.Format(event)
+			assert.NoError(t, err)
+			assert.Equal(t, first, formattedEvent)
+		}
+	})
+}
+
+// upperFormatter implements both Formatter and AppendFormatter
+// producing different output to tell which one was used.
+type upperFormatter struct{}
+
+func (upperFormatter) Format(event log.Event) (string, error) {
+	return fmt.Sprint(event.Message), nil
+}
+
+func (upperFormatter) AppendFormat(dst []byte, event log.Event) ([]byte, error) {
+	return append(dst, strings.ToUpper(fmt.Sprint(event.Message))...), nil
+}
+
+// appendOnlyFormatter implements only AppendFormatter.
+type appendOnlyFormatter struct{}
+
+func (appendOnlyFormatter) AppendFormat(dst []byte, event log.Event) ([]byte, error) {
+	if event.Message == "fail" {
+		return dst, errors.New("cannot format")
+	}
+
+	return append(dst, fmt.Sprint(event.Message)...), nil
+}
+
+func TestAsAppendFormatter(t *testing.T) {
+	event := log.NewEvent(log.LevelVerbose, "hello", nil)
+
+	t.Run("formatter", func(t *testing.T) {
+		formatter := log.AsAppendFormatter(log.SimpleTextFormatter)
+
+		expected, err := log.SimpleTextFormatter.Format(event)
+		assert.NoError(t, err)
+
+		formatted, err := formatter.AppendFormat([]byte("> "), event)
+		assert.NoError(t, err)
+		assert.Equal(t, "> "+expected, string(formatted))
+	})
+
+	t.Run("append formatter", func(t *testing.T) {
+		formatter := log.AsAppendFormatter(upperFormatter{})
+
+		formatted, err := formatter.AppendFormat(nil, event)
+		assert.NoError(t, err)
+		assert.Equal(t, "HELLO", string(formatted))
+	})
+}
+
+func TestAsFormatter(t *testing.T) {
+	formatter := log.AsFormatter(appendOnlyFormatter{})
+
+	formatted, err := formatter.Format(log.NewEvent(log.LevelVerbose, "hello", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", formatted)
+
+	_, err = formatter.Format(log.NewEvent(log.LevelVerbose, "fail", nil))
+	assert.Error(t, err)
+
+	t.Run("formatter", func(t *testing.T) {
+		formatter := log.AsFormatter(upperFormatter{})
+
+		formatted, err := formatter.Format(log.NewEvent(log.LevelVerbose, "hello", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", formatted)
+	})
+}
+
 func TestTemplateFormatter(t *testing.T) {
 	t.Run("empty message", func(t *testing.T) {
 		tmpl, err := template.New("test_empty_message").Parse("")
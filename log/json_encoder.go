@@ -0,0 +1,428 @@
+package log
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPooledBufferSize limits capacity of buffers returned to the pool,
+// so rare huge events don't pin memory.
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// getBuffer takes an empty byte buffer from the pool.
+func getBuffer() *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+
+	return buf
+}
+
+// putBuffer returns given buffer to the pool.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBufferSize {
+		return
+	}
+
+	bufferPool.Put(buf)
+}
+
+var keysPool = sync.Pool{
+	New: func() interface{} {
+		keys := make([]string, 0, 16)
+		return &keys
+	},
+}
+
+// sortedKeys returns pooled slice with sorted keys of given map,
+// the slice must be released with putKeys.
+func sortedKeys(m map[string]interface{}) *[]string {
+	keys := keysPool.Get().(*[]string)
+	*keys = (*keys)[:0]
+
+	for k := range m {
+		*keys = append(*keys, k)
+	}
+
+	sort.Strings(*keys)
+
+	return keys
+}
+
+// putKeys returns given slice of keys to the pool.
+func putKeys(keys *[]string) {
+	keysPool.Put(keys)
+}
+
+// jsonEncoder appends JSON to buffer producing the same output as
+// encoding/json. Common value types are encoded without reflection and
+// allocations, other ones are passed to json.Marshal. Encoding stops
+// at the first error.
+type jsonEncoder struct {
+	buf []byte
+	err error
+}
+
+// result returns encoded buffer or error truncating buffer to given
+// length.
+func (e *jsonEncoder) result(n int) ([]byte, error) {
+	if e.err != nil {
+		return e.buf[:n], e.err
+	}
+
+	return e.buf, nil
+}
+
+// key appends object key followed by colon.
+func (e *jsonEncoder) key(k string) {
+	e.string(k)
+	e.buf = append(e.buf, ':')
+}
+
+// field appends top-level value of event replacing errors which cannot
+// be marshaled to JSON on their own with ErrorInfo and typed nil errors
+// with null.
+func (e *jsonEncoder) field(v interface{}) {
+	if err, ok := v.(error); ok {
+		if isNil(reflect.ValueOf(err)) {
+			e.value(nil)
+			return
+		}
+
+		if _, ok := err.(json.Marshaler); !ok {
+			e.errorInfo(NewErrorInfo(err))
+			return
+		}
+	}
+
+	e.value(v)
+}
+
+// value appends given value.
+func (e *jsonEncoder) value(v interface{}) {
+	if e.err != nil {
+		return
+	}
+
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, "null"...)
+	case string:
+		e.string(v)
+	case bool:
+		e.buf = strconv.AppendBool(e.buf, v)
+	case int:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int8:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int16:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int32:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int64:
+		e.buf = strconv.AppendInt(e.buf, v, 10)
+	case uint:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint8:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint16:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint32:
+		e.buf = strconv.AppendUint(e.buf, uint64(v), 10)
+	case uint64:
+		e.buf = strconv.AppendUint(e.buf, v, 10)
+	case float32:
+		e.float(float64(v), 32)
+	case float64:
+		e.float(v, 64)
+	case time.Duration:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case time.Time:
+		e.time(v)
+	case Extra:
+		e.object(v, false)
+	case map[string]interface{}:
+		e.object(v, false)
+	case []interface{}:
+		e.array(v)
+	case []string:
+		e.strings(v)
+	case ErrorInfo:
+		e.errorInfo(v)
+	case Frame:
+		e.frame(v)
+	case *Frame:
+		if v == nil {
+			e.buf = append(e.buf, "null"...)
+			return
+		}
+
+		e.frame(*v)
+	case Stack:
+		e.stack(v)
+	default:
+		e.marshal(v)
+	}
+}
+
+// marshal appends value encoded with json.Marshal.
+func (e *jsonEncoder) marshal(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	e.buf = append(e.buf, data...)
+}
+
+// object appends map with keys in sorted order. If fields is true, values
+// are encoded as top-level event values.
+func (e *jsonEncoder) object(m map[string]interface{}, fields bool) {
+	if m == nil {
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+
+	keys := sortedKeys(m)
+	defer putKeys(keys)
+
+	e.buf = append(e.buf, '{')
+
+	for i, k := range *keys {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+
+		e.key(k)
+
+		if fields {
+			e.field(m[k])
+		} else {
+			e.value(m[k])
+		}
+	}
+
+	e.buf = append(e.buf, '}')
+}
+
+// array appends slice of arbitrary values.
+func (e *jsonEncoder) array(values []interface{}) {
+	if values == nil {
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+
+	e.buf = append(e.buf, '[')
+
+	for i, v := range values {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+
+		e.value(v)
+	}
+
+	e.buf = append(e.buf, ']')
+}
+
+// strings appends slice of strings.
+func (e *jsonEncoder) strings(values []string) {
+	if values == nil {
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+
+	e.buf = append(e.buf, '[')
+
+	for i, s := range values {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+
+		e.string(s)
+	}
+
+	e.buf = append(e.buf, ']')
+}
+
+// time appends time in RFC 3339 format with nanoseconds as time.Time
+// MarshalJSON does.
+func (e *jsonEncoder) time(t time.Time) {
+	if year := t.Year(); year < 0 || year > 9999 {
+		// Let time.Time report the error.
+		e.marshal(t)
+		return
+	}
+
+	e.buf = append(e.buf, '"')
+	e.buf = t.AppendFormat(e.buf, time.RFC3339Nano)
+	e.buf = append(e.buf, '"')
+}
+
+// float appends floating point number the same way as encoding/json.
+func (e *jsonEncoder) float(f float64, bits int) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		// Let encoding/json report the error.
+		if bits == 32 {
+			e.marshal(float32(f))
+		} else {
+			e.marshal(f)
+		}
+
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, bits)
+
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+}
+
+// frame appends stack frame.
+func (e *jsonEncoder) frame(f Frame) {
+	e.buf = append(e.buf, `{"function":`...)
+	e.string(f.Function)
+	e.buf = append(e.buf, `,"file":`...)
+	e.string(f.File)
+	e.buf = append(e.buf, `,"line":`...)
+	e.buf = strconv.AppendInt(e.buf, int64(f.Line), 10)
+	e.buf = append(e.buf, '}')
+}
+
+// stack appends stack trace.
+func (e *jsonEncoder) stack(s Stack) {
+	if s == nil {
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+
+	e.buf = append(e.buf, '[')
+
+	for i, f := range s {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+
+		e.frame(f)
+	}
+
+	e.buf = append(e.buf, ']')
+}
+
+// errorInfo appends structured representation of error.
+func (e *jsonEncoder) errorInfo(info ErrorInfo) {
+	e.buf = append(e.buf, `{"message":`...)
+	e.string(info.Message)
+	e.buf = append(e.buf, `,"type":`...)
+	e.string(info.Type)
+
+	if len(info.Fields) > 0 {
+		e.buf = append(e.buf, `,"fields":`...)
+		e.object(info.Fields, false)
+	}
+
+	if len(info.Chain) > 0 {
+		e.buf = append(e.buf, `,"chain":[`...)
+
+		for i, wrapped := range info.Chain {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+
+			e.errorInfo(wrapped)
+		}
+
+		e.buf = append(e.buf, ']')
+	}
+
+	e.buf = append(e.buf, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// string appends quoted string escaped the same way as encoding/json
+// does, including HTML characters, U+2028, U+2029 and invalid UTF-8.
+func (e *jsonEncoder) string(s string) {
+	e.buf = append(e.buf, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			e.buf = append(e.buf, s[start:i]...)
+
+			switch b {
+			case '"', '\\':
+				e.buf = append(e.buf, '\\', b)
+			case '\b':
+				e.buf = append(e.buf, '\\', 'b')
+			case '\f':
+				e.buf = append(e.buf, '\\', 'f')
+			case '\n':
+				e.buf = append(e.buf, '\\', 'n')
+			case '\r':
+				e.buf = append(e.buf, '\\', 'r')
+			case '\t':
+				e.buf = append(e.buf, '\\', 't')
+			default:
+				e.buf = append(e.buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+
+			i++
+			start = i
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			e.buf = append(e.buf, s[start:i]...)
+			e.buf = append(e.buf, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			e.buf = append(e.buf, s[start:i]...)
+			e.buf = append(e.buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+		default:
+			i += size
+			continue
+		}
+
+		i += size
+		start = i
+	}
+
+	e.buf = append(e.buf, s[start:]...)
+	e.buf = append(e.buf, '"')
+}
@@ -0,0 +1,251 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+// marshalEvent renders event with encoding/json the way JSONFormatter
+// did before it got its own encoder.
+func marshalEvent(t *testing.T, event log.Event) string {
+	t.Helper()
+
+	message := event.Message
+	if err, ok := message.(error); ok {
+		message = log.NewErrorInfo(err)
+	}
+
+	extra := log.Extra{}
+	for k, v := range event.Extra {
+		if err, ok := v.(error); ok {
+			v = log.NewErrorInfo(err)
+		}
+
+		extra[k] = v
+	}
+
+	event.Message = message
+	event.Extra = extra
+
+	jsonEvent := struct {
+		Level string `json:"level"`
+		*log.Event
+	}{
+		Level: event.Level.String(),
+		Event: &event,
+	}
+
+	m, err := json.Marshal(jsonEvent)
+	assert.NoError(t, err)
+
+	return string(m)
+}
+
+func TestJSONFormatter_Encoding(t *testing.T) {
+	eventTime := time.Date(2021, 5, 17, 10, 30, 0, 123456789, time.FixedZone("MSK", 3*60*60))
+
+	cases := []struct {
+		name    string
+		message interface{}
+		extra   log.Extra
+	}{
+		{"plain string", "hello", nil},
+		{"escaped string", "quote \" backslash \\ <tag> & \x01\n\r\t \u2028\u2029 \xff юникод", nil},
+		{"error message", fmt.Errorf("wrap: %w", errors.New("inner")), nil},
+		{"non-string message", 42, nil},
+		{
+			"scalars",
+			"scalars",
+			log.Extra{
+				"string": "value", "bool": true, "nil": nil,
+				"int": -1, "int8": int8(-8), "int16": int16(-16), "int32": int32(-32), "int64": int64(-64),
+				"uint": uint(1), "uint8": uint8(8), "uint16": uint16(16), "uint32": uint32(32), "uint64": uint64(64),
+				"duration": 1500 * time.Millisecond,
+			},
+		},
+		{
+			"floats",
+			"floats",
+			log.Extra{
+				"zero": 0.0, "small": 1e-7, "large": 1e21, "regular": 3.14, "negative": -2.5e-10,
+				"float32": float32(0.1), "small32": float32(1e-7), "large32": float32(1e22),
+			},
+		},
+		{
+			"composite",
+			"composite",
+			log.Extra{
+				"time":    eventTime,
+				"map":     map[string]interface{}{"b": 1, "a": []interface{}{"x", 2, nil}},
+				"extra":   log.Extra{"nested": "<>"},
+				"strings": []string{"a", "b"},
+				"bytes":   []byte("raw"),
+				"ip":      net.IPv4(127, 0, 0, 1),
+				"struct":  struct{ Name string }{"name"},
+				"error":   errors.New("boom"),
+				"level":   log.LevelImportant,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			event := log.Event{
+				Time:    eventTime,
+				Level:   log.LevelVerbose,
+				Message: c.message,
+				Extra:   c.extra,
+				Caller:  &log.Frame{Function: "main.main", File: "/app/main.go", Line: 42},
+				Stack: log.Stack{
+					{Function: "main.run", File: "/app/run.go", Line: 7},
+					{Function: "main.main", File: "/app/main.go", Line: 42},
+				},
+			}
+
+			formatted, err := log.JSONFormatter{}.Format(event)
+			assert.NoError(t, err)
+			assert.Equal(t, marshalEvent(t, event), formatted)
+		})
+	}
+
+	t.Run("unsupported value", func(t *testing.T) {
+		event := log.NewEvent(log.LevelVerbose, "hello", log.Extra{"nan": math.NaN()})
+
+		dst := []byte("prefix")
+		formatted, err := log.JSONFormatter{}.AppendFormat(dst, event)
+
+		assert.Error(t, err)
+		assert.Equal(t, "prefix", string(formatted))
+	})
+}
+
+func TestJSONFormatter_AppendFormat(t *testing.T) {
+	event := log.NewEvent(log.LevelVerbose, "hello", log.Extra{"foo": "bar"})
+	formatter := log.JSONFormatter{}
+
+	expected, err := formatter.Format(event)
+	assert.NoError(t, err)
+
+	formatted, err := formatter.AppendFormat([]byte("prefix "), event)
+	assert.NoError(t, err)
+	assert.Equal(t, "prefix "+expected, string(formatted))
+
+	t.Run("empty message", func(t *testing.T) {
+		formatted, err := formatter.AppendFormat(nil, log.NewEvent(log.LevelVerbose, nil, nil))
+
+		assert.NoError(t, err)
+		assert.Empty(t, formatted)
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		event := log.NewEvent(log.LevelVerbose, "hello", log.Extra{
+			"user_id": 42,
+			"path":    "/api/v1/users",
+			"elapsed": 1500 * time.Microsecond,
+			"ok":      true,
+		})
+		buf := make([]byte, 0, 1024)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			buf, _ = formatter.AppendFormat(buf[:0], event)
+		})
+
+		assert.Less(t, allocs, 1.0)
+	})
+}
+
+func TestJSONFormatter_Encode(t *testing.T) {
+	event := log.NewEvent(log.LevelVerbose, "hello", log.Extra{"foo": "bar"})
+	formatter := log.JSONFormatter{}
+
+	expected, err := formatter.Format(event)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, formatter.Encode(&buf, event))
+	assert.NoError(t, formatter.Encode(&buf, log.NewEvent(log.LevelVerbose, nil, nil)))
+	assert.Equal(t, expected+"\n", buf.String())
+}
+
+func benchmarkEvent() log.Event {
+	return log.Event{
+		Time:    time.Now(),
+		Level:   log.LevelImportant,
+		Message: "request handled",
+		Extra: log.Extra{
+			"method":  "GET",
+			"path":    "/api/v1/users",
+			"status":  200,
+			"elapsed": 1500 * time.Microsecond,
+			"bytes":   uint64(5120),
+			"cached":  false,
+		},
+	}
+}
+
+func BenchmarkJSONFormatter_Format(b *testing.B) {
+	event := benchmarkEvent()
+	formatter := log.JSONFormatter{}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = formatter.Format(event)
+	}
+}
+
+func BenchmarkJSONFormatter_AppendFormat(b *testing.B) {
+	event := benchmarkEvent()
+	formatter := log.JSONFormatter{}
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf, _ = formatter.AppendFormat(buf[:0], event)
+	}
+}
+
+func BenchmarkJSONFormatter_Encode(b *testing.B) {
+	event := benchmarkEvent()
+	formatter := log.JSONFormatter{}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = formatter.Encode(discard{}, event)
+	}
+}
+
+func BenchmarkJSONMarshal(b *testing.B) {
+	event := benchmarkEvent()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(struct {
+			Level string `json:"level"`
+			*log.Event
+		}{
+			Level: event.Level.String(),
+			Event: &event,
+		})
+	}
+}
+
+// discard is io.Writer which ignores everything written to it.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -93,6 +93,16 @@ func Write(ctx context.Context, level Level, msg interface{}, extra Extra) {
 	DefaultLogger.Write(ctx, level, msg, extra)
 }
 
+// With returns a child of default logger with given extra bound to every event.
+func With(extra Extra) *Logger {
+	return DefaultLogger.With(extra)
+}
+
+// SetLevel changes logging level of default logger.
+func SetLevel(level Level) {
+	DefaultLogger.SetLevel(level)
+}
+
 // PreHook registers given hook in logger to be executed before log event was written to output.
 func PreHook(h Hook) {
 	DefaultLogger.PreHook(h)
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter is used to output logs in logfmt format:
+//
+//	time=2021-05-01T12:00:00Z level=IMPORTANT msg="hello world" user.id=42
+//
+// Extra keys are sorted, nested maps are flattened with dotted keys.
+type LogfmtFormatter struct{}
+
+// Format converts given event to logfmt line.
+func (f LogfmtFormatter) Format(event Event) (string, error) {
+	if event.Message == nil {
+		return "", nil
+	}
+
+	var builder strings.Builder
+
+	writeLogfmtPair(&builder, "time", event.Time.Format(time.RFC3339Nano))
+	writeLogfmtPair(&builder, "level", event.Level.String())
+	writeLogfmtPair(&builder, "msg", TextValue(event.Message))
+
+	if event.Caller != nil {
+		writeLogfmtPair(&builder, "caller", event.Caller.String())
+	}
+
+	FlattenExtra(event.Extra, ".", func(key string, value interface{}) {
+		writeLogfmtPair(&builder, logfmtKey(key), TextValue(value))
+	})
+
+	if event.Stack != nil {
+		writeLogfmtPair(&builder, "stack", event.Stack.String())
+	}
+
+	return builder.String(), nil
+}
+
+// writeLogfmtPair writes key=value pair separated from previous one with space,
+// value is quoted if needed.
+func writeLogfmtPair(builder *strings.Builder, key, value string) {
+	if builder.Len() > 0 {
+		builder.WriteByte(' ')
+	}
+
+	builder.WriteString(key)
+	builder.WriteByte('=')
+
+	if needsLogfmtQuoting(value) {
+		builder.WriteString(strconv.Quote(value))
+		return
+	}
+
+	builder.WriteString(value)
+}
+
+// logfmtKey replaces characters not allowed in keys with underscore.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return '_'
+		}
+
+		return r
+	}, key)
+}
+
+// needsLogfmtQuoting reports whether value contains characters which
+// must be quoted.
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
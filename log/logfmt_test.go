@@ -0,0 +1,94 @@
+package log_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+	formatter := &log.LogfmtFormatter{}
+	eventTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("empty message", func(t *testing.T) {
+		formatted, err := formatter.Format(log.NewEvent(log.LevelVerbose, nil, log.Extra{"foo": "bar"}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", formatted)
+	})
+
+	t.Run("usual case", func(t *testing.T) {
+		formatted, err := formatter.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: "hello world",
+			Extra:   log.Extra{"user_id": 42, "active": true},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, `time=2021-05-01T12:00:00Z level=IMPORTANT msg="hello world" active=true user_id=42`, formatted)
+	})
+
+	t.Run("escaping", func(t *testing.T) {
+		formatted, err := formatter.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelVerbose,
+			Message: errors.New(`unexpected "token"`),
+			Extra: log.Extra{
+				"multiline":   "a\nb",
+				"path":        `C:\app`,
+				"empty":       "",
+				"eq":          "a=b",
+				"nil":         nil,
+				"bad key=\"x": "y",
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			`time=2021-05-01T12:00:00Z level=VERBOSE msg="unexpected \"token\"" bad_key__x=y empty="" eq="a=b" multiline="a\nb" nil=null path="C:\\app"`,
+			formatted,
+		)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		var nilErr *os.PathError
+
+		formatted, err := formatter.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: nilErr,
+			Extra:   log.Extra{"err": nilErr},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, `time=2021-05-01T12:00:00Z level=IMPORTANT msg=null err=null`, formatted)
+	})
+
+	t.Run("nested extra", func(t *testing.T) {
+		formatted, err := formatter.Format(log.Event{
+			Time:    eventTime,
+			Level:   log.LevelVerbose,
+			Message: "hello",
+			Extra: log.Extra{
+				"user": log.Extra{"name": "Jon Snow", "id": 1},
+				"http": map[string]interface{}{"request": map[string]interface{}{"method": "GET"}, "status": 200},
+				"at":   eventTime,
+			},
+			Caller: &log.Frame{Function: "main.main", File: "main.go", Line: 7},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			`time=2021-05-01T12:00:00Z level=VERBOSE msg=hello caller=main.go:7 at=2021-05-01T12:00:00Z http.request.method=GET http.status=200 user.id=1 user.name="Jon Snow"`,
+			formatted,
+		)
+	})
+}
@@ -5,15 +5,24 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 )
 
 // Logger ...
 type Logger struct {
-	mx sync.Mutex
+	mx *sync.Mutex
 
-	level     Level
-	output    io.Writer
-	formatter Formatter
+	level *atomic.Value
+	sinks []Sink
+
+	errorHandler ErrorHandler
+	stats        *loggerStats
+	queue        *asyncQueue
+	reportCaller bool
+	captureStack bool
+	stackLevel   Level
+
+	extra Extra
 
 	preHooks  []Hook
 	postHooks []Hook
@@ -22,13 +31,47 @@ type Logger struct {
 // Hook is a function being called before event was sent to logger output.
 type Hook func(context.Context, *Event)
 
-// NewLogger returns a new instance of Logger.
-func NewLogger(level Level, output io.Writer, formatter Formatter) *Logger {
-	return &Logger{
-		level:     level,
-		output:    output,
-		formatter: formatter,
+// Option configures Logger created with NewLogger.
+type Option func(*Logger)
+
+// NewLogger returns a new instance of Logger writing events to given output.
+func NewLogger(level Level, output io.Writer, formatter Formatter, opts ...Option) *Logger {
+	return NewSinkLogger(level, []Sink{{Output: output, Formatter: formatter}}, opts...)
+}
+
+// NewSinkLogger returns a new instance of Logger fanning out events
+// to given sinks. Events with level lower than given level are not
+// sent to any sink regardless of sink's own level.
+func NewSinkLogger(level Level, sinks []Sink, opts ...Option) *Logger {
+	l := &Logger{
+		mx:           &sync.Mutex{},
+		level:        &atomic.Value{},
+		sinks:        sinks,
+		errorHandler: PanicOnError,
+		stats:        &loggerStats{},
 	}
+	l.level.Store(level)
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// With returns a child logger which shares output, formatter, level and
+// hooks with l and binds given extra to every event it writes. Bound extra
+// of l is inherited, keys of given extra take precedence over it.
+//
+// Bound extra has the lowest precedence: extra from context and call-site
+// extra override it. Hooks registered in child logger do not affect l.
+func (l *Logger) With(extra Extra) *Logger {
+	child := *l
+	child.extra = mergedExtra(l.extra, extra)
+	child.preHooks = l.preHooks[:len(l.preHooks):len(l.preHooks)]
+	child.postHooks = l.postHooks[:len(l.postHooks):len(l.postHooks)]
+
+	return &child
 }
 
 // Verbose writes a message with verbose level.
@@ -67,35 +110,164 @@ func (l *Logger) Writef(ctx context.Context, level Level, msg string, values ...
 }
 
 // Write writes a message with given level and extra.
+// Extra bound to logger with With and extra attached to ctx with WithExtra
+// are merged into event's extra, keys of given extra take precedence.
+//
+// If event cannot be formatted or written to sink, it's passed to logger's
+// ErrorHandler. Post-hooks are not called if event was not written to any
+// of sinks accepting it.
 func (l *Logger) Write(ctx context.Context, level Level, msg interface{}, extra Extra) {
-	l.mx.Lock()
-	defer l.mx.Unlock()
-
 	if msg == nil {
 		return
 	}
 
-	event := NewEvent(level, msg, extra)
-	for _, h := range l.preHooks {
-		h(ctx, &event)
+	l.write(ctx, NewEvent(level, msg, l.eventExtra(ctx, extra)))
+}
+
+// WriteEvent writes prepared event. It's intended for adapters of other
+// logging APIs which provide own event time and caller. Extra bound to
+// logger and extra attached to ctx are merged into event's extra, keys of
+// event's extra take precedence. Event's caller is kept only if logger
+// reports caller and captured if it's missing.
+func (l *Logger) WriteEvent(ctx context.Context, event Event) {
+	if event.Message == nil {
+		return
+	}
+
+	event.Extra = l.eventExtra(ctx, event.Extra)
+	if event.Extra == nil {
+		event.Extra = Extra{}
+	}
+
+	if !l.reportCaller {
+		event.Caller = nil
 	}
 
-	if l.level.Gt(level) {
+	l.write(ctx, event)
+}
+
+// write sends event to sinks directly or through asynchronous queue.
+func (l *Logger) write(ctx context.Context, event Event) {
+	if l.queue != nil {
+		if l.prepare(ctx, &event) {
+			l.capture(&event)
+
+			// Extra may be the caller's own map, which can be reused
+			// once Write returns.
+			event.Extra = mergedExtra(event.Extra)
+
+			l.queue.push(ctx, queuedEvent{logger: l, ctx: ctx, event: event})
+		}
+
 		return
 	}
 
-	formattedEvent, err := l.formatter.Format(event)
-	if err != nil {
-		panic(err)
+	for _, err := range l.writeSync(ctx, &event) {
+		l.errorHandler(ctx, event, err)
 	}
+}
+
+// writeSync prepares and delivers event holding the lock. Errors are
+// returned to be reported outside of the lock.
+func (l *Logger) writeSync(ctx context.Context, event *Event) []error {
+	l.mx.Lock()
+	defer l.mx.Unlock()
 
-	if _, err := l.output.Write([]byte(formattedEvent + "\n")); err != nil {
-		panic(err)
+	if !l.prepare(ctx, event) {
+		return nil
+	}
+
+	l.capture(event)
+
+	return l.deliver(ctx, event)
+}
+
+// capture fills caller and stack trace of event which passed level check.
+// It's deferred until then as walking goroutine stack is costly, so
+// pre-hooks don't see caller and stack trace captured by logger.
+func (l *Logger) capture(event *Event) {
+	if l.reportCaller && event.Caller == nil {
+		event.Caller = callerFrame()
+	}
+
+	if event.Stack == nil {
+		event.Stack = l.eventStack(event.Level, event.Message)
+	}
+}
+
+// prepare runs pre-hooks and reports whether event passes logger level.
+func (l *Logger) prepare(ctx context.Context, event *Event) bool {
+	for _, h := range l.preHooks {
+		h(ctx, event)
+	}
+
+	return !l.Level().Gt(event.Level)
+}
+
+// deliver sends event to sinks accepting it and runs post-hooks.
+// Errors are counted in logger stats.
+func (l *Logger) deliver(ctx context.Context, event *Event) []error {
+	var (
+		accepted int
+		errs     []error
+	)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	for i := range l.sinks {
+		sink := &l.sinks[i]
+		if !sink.accepts(event) {
+			continue
+		}
+
+		accepted++
+
+		var err error
+
+		*buf, err = sink.appendFormat((*buf)[:0], event)
+		if err != nil {
+			atomic.AddUint64(&l.stats.formatErrors, 1)
+			errs = append(errs, fmt.Errorf("format event: %w", err))
+
+			continue
+		}
+
+		*buf = append(*buf, '\n')
+
+		if _, err := sink.Output.Write(*buf); err != nil {
+			atomic.AddUint64(&l.stats.writeErrors, 1)
+			errs = append(errs, fmt.Errorf("write event: %w", err))
+		}
+	}
+
+	if accepted > 0 && len(errs) == accepted {
+		return errs
 	}
 
 	for _, h := range l.postHooks {
-		h(ctx, &event)
+		h(ctx, event)
 	}
+
+	return errs
+}
+
+// Level returns current logging level of logger.
+func (l *Logger) Level() Level {
+	return l.level.Load().(Level)
+}
+
+// SetLevel changes logging level of logger. It's safe to call SetLevel
+// concurrently with writing events. Child loggers created with With
+// share level with their parent.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(level)
+}
+
+// Stats returns counters of events dropped by logger. Child loggers
+// created with With share counters with their parent.
+func (l *Logger) Stats() Stats {
+	return l.stats.snapshot()
 }
 
 // PreHook registers given hook in logger to be executed before log event was written to output.
@@ -107,3 +279,14 @@ func (l *Logger) PreHook(h Hook) {
 func (l *Logger) PostHook(h Hook) {
 	l.postHooks = append(l.postHooks, h)
 }
+
+// eventExtra merges bound extra, extra from ctx and given extra into a new Extra.
+// Given extra is returned as is when there is nothing to merge it with.
+func (l *Logger) eventExtra(ctx context.Context, extra Extra) Extra {
+	fromCtx := extraFrom(ctx)
+	if len(l.extra) == 0 && len(fromCtx) == 0 {
+		return extra
+	}
+
+	return mergedExtra(l.extra, fromCtx, extra)
+}
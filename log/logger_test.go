@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tomakado/logo/log"
 )
 
@@ -238,3 +241,171 @@ func TestLogger_PostHook(t *testing.T) {
 	logger.Write(context.Background(), log.LevelVerbose, "hello", nil)
 	assert.True(t, hookCalled)
 }
+
+func TestLogger_With(t *testing.T) {
+	var buf bytes.Buffer
+
+	parent := log.NewLogger(log.LevelVerbose, &buf, &log.JSONFormatter{})
+
+	var parentEvent *log.Event
+	parent.PostHook(func(_ context.Context, e *log.Event) {
+		parentEvent = e
+	})
+
+	t.Run("bound extra", func(t *testing.T) {
+		child := parent.With(log.Extra{"component": "db"})
+
+		var childEvent *log.Event
+		child.PostHook(func(_ context.Context, e *log.Event) {
+			childEvent = e
+		})
+
+		buf.Reset()
+		child.Verbose(context.Background(), "hello")
+
+		assert.Equal(t, log.Extra{"component": "db"}, childEvent.Extra)
+		assert.Equal(t, childEvent, parentEvent)
+		assert.Contains(t, buf.String(), `"component":"db"`)
+	})
+
+	t.Run("children compose", func(t *testing.T) {
+		child := parent.With(log.Extra{"component": "db", "shard": 1})
+		grandchild := child.With(log.Extra{"shard": 2, "table": "users"})
+
+		grandchild.Verbose(context.Background(), "hello")
+		assert.Equal(t, log.Extra{"component": "db", "shard": 2, "table": "users"}, parentEvent.Extra)
+
+		child.Verbose(context.Background(), "hello")
+		assert.Equal(t, log.Extra{"component": "db", "shard": 1}, parentEvent.Extra)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		child := parent.With(log.Extra{"a": "bound", "b": "bound", "c": "bound"})
+		ctx := log.WithExtra(context.Background(), log.Extra{"b": "context", "c": "context"})
+
+		child.VerboseX(ctx, "hello", log.Extra{"c": "call"})
+		assert.Equal(t, log.Extra{"a": "bound", "b": "context", "c": "call"}, parentEvent.Extra)
+	})
+
+	t.Run("child hooks do not affect parent", func(t *testing.T) {
+		child := parent.With(nil)
+
+		var childHookCalls int
+		child.PreHook(func(_ context.Context, e *log.Event) {
+			childHookCalls++
+		})
+
+		parent.Verbose(context.Background(), "hello")
+		assert.Equal(t, 0, childHookCalls)
+
+		child.Verbose(context.Background(), "hello")
+		assert.Equal(t, 1, childHookCalls)
+	})
+
+	t.Run("parent is not affected by bound extra", func(t *testing.T) {
+		parent.With(log.Extra{"foo": "bar"})
+
+		parent.Verbose(context.Background(), "hello")
+		assert.Empty(t, parentEvent.Extra)
+	})
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := log.NewLogger(log.LevelImportant, &buf, &log.JSONFormatter{})
+	child := logger.With(log.Extra{"foo": "bar"})
+
+	assert.Equal(t, log.LevelImportant, logger.Level())
+
+	logger.Verbose(context.Background(), "hello")
+	assert.Equal(t, 0, buf.Len())
+
+	child.SetLevel(log.LevelVerbose)
+	assert.Equal(t, log.LevelVerbose, logger.Level())
+
+	logger.Verbose(context.Background(), "hello")
+	assert.Contains(t, buf.String(), "hello")
+}
+
+func TestLogger_SetLevel_Concurrent(t *testing.T) {
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			child := logger.With(log.Extra{"foo": "bar"})
+			for j := 0; j < 100; j++ {
+				child.Verbose(context.Background(), "hello")
+				logger.Important(context.Background(), "hello")
+			}
+		}()
+	}
+
+	levels := []log.Level{log.LevelVerbose, log.LevelImportant}
+	for i := 0; i < 100; i++ {
+		logger.SetLevel(levels[i%len(levels)])
+		_ = logger.Level()
+	}
+
+	wg.Wait()
+}
+
+func TestLogger_WriteEvent(t *testing.T) {
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}).With(log.Extra{"bound": true, "foo": "bound"})
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	eventTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	caller := &log.Frame{Function: "main.main", File: "main.go", Line: 42}
+
+	t.Run("usual case", func(t *testing.T) {
+		logger.WriteEvent(context.Background(), log.Event{
+			Time:    eventTime,
+			Level:   log.LevelImportant,
+			Message: "hello",
+			Extra:   log.Extra{"foo": "bar"},
+			Caller:  caller,
+		})
+
+		assert.Equal(t, eventTime, loggedEvent.Time)
+		assert.Equal(t, log.LevelImportant, loggedEvent.Level)
+		assert.Equal(t, "hello", loggedEvent.Message)
+		assert.Equal(t, log.Extra{"bound": true, "foo": "bar"}, loggedEvent.Extra)
+		assert.Nil(t, loggedEvent.Caller)
+	})
+
+	t.Run("caller is kept if logger reports caller", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}, log.ReportCaller())
+		logger.PostHook(func(_ context.Context, e *log.Event) {
+			loggedEvent = e
+		})
+
+		logger.WriteEvent(context.Background(), log.Event{Level: log.LevelVerbose, Message: "hello", Caller: caller})
+		assert.Equal(t, caller, loggedEvent.Caller)
+		assert.Equal(t, log.Extra{}, loggedEvent.Extra)
+
+		logger.WriteEvent(context.Background(), log.Event{Level: log.LevelVerbose, Message: "hello"})
+		require.NotNil(t, loggedEvent.Caller)
+		assert.True(t, strings.HasPrefix(loggedEvent.Caller.Function, "github.com/tomakado/logo/log_test.TestLogger_WriteEvent"))
+	})
+}
+
+func BenchmarkLogger_Write(b *testing.B) {
+	ctx := context.Background()
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{})
+	extra := log.Extra{"method": "GET", "path": "/api/v1/users", "status": 200}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Write(ctx, log.LevelImportant, "request handled", extra)
+	}
+}
@@ -0,0 +1,132 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Errors returned by level registry.
+var (
+	ErrDuplicateLevel = errors.New("level is already registered")
+	ErrUnknownLevel   = errors.New("unknown level")
+)
+
+// levelRegistry holds levels known by name and value.
+var levelRegistry = struct {
+	mx      sync.RWMutex
+	byName  map[string]Level
+	byValue map[uint8]Level
+}{
+	byName:  make(map[string]Level),
+	byValue: make(map[uint8]Level),
+}
+
+// RegisterLevel makes given level available for ParseLevel and LevelByValue.
+// Error is returned if level with the same value or name (case-insensitively)
+// is already registered. LevelVerbose and LevelImportant are registered
+// out of box.
+func RegisterLevel(level Level) error {
+	levelRegistry.mx.Lock()
+	defer levelRegistry.mx.Unlock()
+
+	name := strings.ToUpper(level.String())
+
+	if existing, ok := levelRegistry.byName[name]; ok {
+		return fmt.Errorf("%w: name %q is taken by %s(%d)", ErrDuplicateLevel, level.String(), existing, existing.Uint8())
+	}
+
+	if existing, ok := levelRegistry.byValue[level.Uint8()]; ok {
+		return fmt.Errorf("%w: value %d is taken by %s", ErrDuplicateLevel, level.Uint8(), existing)
+	}
+
+	levelRegistry.byName[name] = level
+	levelRegistry.byValue[level.Uint8()] = level
+
+	return nil
+}
+
+// MustRegisterLevel is like RegisterLevel but panics on error.
+func MustRegisterLevel(level Level) Level {
+	if err := RegisterLevel(level); err != nil {
+		panic(err)
+	}
+
+	return level
+}
+
+// ParseLevel returns registered level with given name (case-insensitive)
+// or, if there is no such name, with given numeric value.
+func ParseLevel(s string) (Level, error) {
+	levelRegistry.mx.RLock()
+	level, ok := levelRegistry.byName[strings.ToUpper(s)]
+	levelRegistry.mx.RUnlock()
+
+	if ok {
+		return level, nil
+	}
+
+	value, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return Level{}, fmt.Errorf("%w: %q", ErrUnknownLevel, s)
+	}
+
+	return LevelByValue(uint8(value))
+}
+
+// LevelByValue returns registered level with given numeric value.
+func LevelByValue(value uint8) (Level, error) {
+	levelRegistry.mx.RLock()
+	defer levelRegistry.mx.RUnlock()
+
+	level, ok := levelRegistry.byValue[value]
+	if !ok {
+		return Level{}, fmt.Errorf("%w: %d", ErrUnknownLevel, value)
+	}
+
+	return level, nil
+}
+
+// Levels returns all registered levels ordered by numeric value.
+func Levels() []Level {
+	levelRegistry.mx.RLock()
+
+	levels := make([]Level, 0, len(levelRegistry.byValue))
+	for _, level := range levelRegistry.byValue {
+		levels = append(levels, level)
+	}
+
+	levelRegistry.mx.RUnlock()
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[j].Gt(levels[i])
+	})
+
+	return levels
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.repr), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Text is parsed with ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+
+	return nil
+}
+
+func init() {
+	MustRegisterLevel(LevelVerbose)
+	MustRegisterLevel(LevelImportant)
+}
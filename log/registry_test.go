@@ -0,0 +1,95 @@
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+var levelCritical = log.MustRegisterLevel(log.NewLevel(30, "CRITICAL"))
+
+func TestRegisterLevel(t *testing.T) {
+	t.Run("duplicate name", func(t *testing.T) {
+		err := log.RegisterLevel(log.NewLevel(31, "critical"))
+		assert.True(t, errors.Is(err, log.ErrDuplicateLevel))
+	})
+
+	t.Run("duplicate value", func(t *testing.T) {
+		err := log.RegisterLevel(log.NewLevel(30, "FATAL"))
+		assert.True(t, errors.Is(err, log.ErrDuplicateLevel))
+	})
+
+	t.Run("must register", func(t *testing.T) {
+		assert.Panics(t, func() {
+			log.MustRegisterLevel(log.LevelVerbose)
+		})
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		assert.Equal(t, []log.Level{log.LevelVerbose, log.LevelImportant, levelCritical}, log.Levels())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		level, err := log.ParseLevel("Critical")
+		assert.NoError(t, err)
+		assert.Equal(t, levelCritical, level)
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected log.Level
+		err      error
+	}{
+		{input: "VERBOSE", expected: log.LevelVerbose},
+		{input: "important", expected: log.LevelImportant},
+		{input: "Important", expected: log.LevelImportant},
+		{input: "10", expected: log.LevelVerbose},
+		{input: "20", expected: log.LevelImportant},
+		{input: "42", err: log.ErrUnknownLevel},
+		{input: "256", err: log.ErrUnknownLevel},
+		{input: "debug", err: log.ErrUnknownLevel},
+		{input: "", err: log.ErrUnknownLevel},
+	}
+
+	for _, tc := range cases {
+		level, err := log.ParseLevel(tc.input)
+		if tc.err != nil {
+			assert.True(t, errors.Is(err, tc.err), tc.input)
+			continue
+		}
+
+		assert.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, level, tc.input)
+	}
+}
+
+func TestLevelByValue(t *testing.T) {
+	level, err := log.LevelByValue(20)
+	assert.NoError(t, err)
+	assert.Equal(t, log.LevelImportant, level)
+
+	_, err = log.LevelByValue(42)
+	assert.True(t, errors.Is(err, log.ErrUnknownLevel))
+}
+
+func TestLevel_Text(t *testing.T) {
+	var config struct {
+		Level log.Level `json:"level"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"level":"verbose"}`), &config))
+	assert.Equal(t, log.LevelVerbose, config.Level)
+
+	config.Level = log.LevelImportant
+	m, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"IMPORTANT"}`, string(m))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"level":"debug"}`), &config))
+}
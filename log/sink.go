@@ -0,0 +1,42 @@
+package log
+
+import "io"
+
+// Sink is a destination of log events with its own formatter and level.
+type Sink struct {
+	// Output is a writer formatted events are written to.
+	Output io.Writer
+	// Formatter converts events to string before writing to output.
+	// AppendFormat is used instead of Format if formatter implements
+	// AppendFormatter.
+	Formatter Formatter
+	// Level is minimal level of events written to sink.
+	// Zero value accepts events of any level.
+	Level Level
+	// Filter returns false for events which must not be written to sink.
+	// It's optional and compatible with hooks.Filter.
+	Filter func(e *Event) bool
+}
+
+// NewSink creates a new instance of Sink with given output, formatter and level.
+func NewSink(output io.Writer, formatter Formatter, level Level) Sink {
+	return Sink{
+		Output:    output,
+		Formatter: formatter,
+		Level:     level,
+	}
+}
+
+// accepts returns true if event passes sink's level and filter.
+func (s *Sink) accepts(e *Event) bool {
+	if s.Level.Gt(e.Level) {
+		return false
+	}
+
+	return s.Filter == nil || s.Filter(e)
+}
+
+// appendFormat appends event formatted with sink's formatter to dst.
+func (s *Sink) appendFormat(dst []byte, e *Event) ([]byte, error) {
+	return AsAppendFormatter(s.Formatter).AppendFormat(dst, *e)
+}
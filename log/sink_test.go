@@ -0,0 +1,139 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+func TestNewSinkLogger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sink levels and formatters", func(t *testing.T) {
+		var jsonBuf, textBuf bytes.Buffer
+
+		logger := log.NewSinkLogger(log.LevelVerbose, []log.Sink{
+			log.NewSink(&jsonBuf, &log.JSONFormatter{}, log.LevelImportant),
+			log.NewSink(&textBuf, log.SimpleTextFormatter, log.LevelVerbose),
+		})
+
+		logger.Verbose(ctx, "verbose")
+		logger.Important(ctx, "important")
+
+		jsonLines := strings.Split(strings.TrimSpace(jsonBuf.String()), "\n")
+		assert.Len(t, jsonLines, 1)
+
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(jsonLines[0]), &decoded))
+		assert.Equal(t, "important", decoded["message"])
+
+		textLines := strings.Split(strings.TrimSpace(textBuf.String()), "\n")
+		assert.Len(t, textLines, 2)
+		assert.True(t, strings.HasPrefix(textLines[0], "VERBOSE @ "))
+		assert.True(t, strings.HasPrefix(textLines[1], "IMPORTANT @ "))
+	})
+
+	t.Run("logger level beats sink level", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger := log.NewSinkLogger(log.LevelImportant, []log.Sink{
+			{Output: &buf, Formatter: &log.JSONFormatter{}},
+		})
+
+		logger.Verbose(ctx, "hello")
+		assert.Equal(t, 0, buf.Len())
+	})
+
+	t.Run("sink filter", func(t *testing.T) {
+		var auditBuf, allBuf bytes.Buffer
+
+		logger := log.NewSinkLogger(log.LevelVerbose, []log.Sink{
+			{
+				Output:    &auditBuf,
+				Formatter: &log.JSONFormatter{},
+				Filter: func(e *log.Event) bool {
+					_, ok := e.Extra["audit"]
+					return ok
+				},
+			},
+			{Output: &allBuf, Formatter: &log.JSONFormatter{}},
+		})
+
+		logger.Verbose(ctx, "regular")
+		logger.VerboseX(ctx, "audit", log.Extra{"audit": true})
+
+		assert.Equal(t, 1, strings.Count(auditBuf.String(), "\n"))
+		assert.Equal(t, 2, strings.Count(allBuf.String(), "\n"))
+	})
+
+	t.Run("failed sink does not affect others", func(t *testing.T) {
+		var (
+			buf            bytes.Buffer
+			errs           []error
+			postHookCalled bool
+		)
+
+		logger := log.NewSinkLogger(
+			log.LevelVerbose,
+			[]log.Sink{
+				{Output: errorWriter{}, Formatter: &log.JSONFormatter{}},
+				{Output: &buf, Formatter: &log.JSONFormatter{}},
+			},
+			log.OnError(func(_ context.Context, _ log.Event, err error) {
+				errs = append(errs, err)
+			}),
+		)
+		logger.PostHook(func(_ context.Context, _ *log.Event) {
+			postHookCalled = true
+		})
+
+		logger.Verbose(ctx, "hello")
+
+		assert.Len(t, errs, 1)
+		assert.True(t, postHookCalled)
+		assert.Contains(t, buf.String(), "hello")
+		assert.Equal(t, log.Stats{WriteErrors: 1}, logger.Stats())
+	})
+
+	t.Run("all sinks failed", func(t *testing.T) {
+		var postHookCalled bool
+
+		logger := log.NewSinkLogger(
+			log.LevelVerbose,
+			[]log.Sink{
+				{Output: errorWriter{}, Formatter: &log.JSONFormatter{}},
+				{Output: &bytes.Buffer{}, Formatter: errorFormatter{}},
+			},
+			log.OnError(log.DropOnError),
+		)
+		logger.PostHook(func(_ context.Context, _ *log.Event) {
+			postHookCalled = true
+		})
+
+		logger.Verbose(ctx, "hello")
+
+		assert.False(t, postHookCalled)
+		assert.Equal(t, log.Stats{FormatErrors: 1, WriteErrors: 1}, logger.Stats())
+	})
+}
+
+func TestSink_AppendFormatter(t *testing.T) {
+	ctx := context.Background()
+
+	var preferred, adapted bytes.Buffer
+
+	logger := log.NewSinkLogger(log.LevelVerbose, []log.Sink{
+		{Output: &preferred, Formatter: upperFormatter{}},
+		{Output: &adapted, Formatter: log.AsFormatter(appendOnlyFormatter{})},
+	})
+
+	logger.Verbose(ctx, "hello")
+
+	assert.Equal(t, "HELLO\n", preferred.String())
+	assert.Equal(t, "hello\n", adapted.String())
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is maximal number of frames captured in stack trace.
+const maxStackDepth = 64
+
+// Stack is a goroutine stack trace, the innermost call goes first.
+type Stack []Frame
+
+// String renders stack as indented block in the same layout as Go
+// runtime prints panics.
+func (s Stack) String() string {
+	var builder strings.Builder
+
+	for i, frame := range s {
+		if i > 0 {
+			builder.WriteByte('\n')
+		}
+
+		builder.WriteString("\t" + frame.Function + "\n\t\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+	}
+
+	return builder.String()
+}
+
+// CaptureStack makes logger capture stack trace of events with level greater
+// than or equal to given level and of events with error message and store it
+// in Event.Stack. If error or any error in its chain carries its own stack
+// trace (via Callers() []uintptr method or StackTrace() method returning
+// slice of program counters like github.com/pkg/errors does), stack trace
+// of the innermost such error is used instead of logger's caller stack.
+// Stack trace is captured only for events passing level check, after
+// pre-hooks are called.
+func CaptureStack(level Level) Option {
+	return func(l *Logger) {
+		l.captureStack = true
+		l.stackLevel = level
+	}
+}
+
+// eventStack returns stack trace for event with given message and level
+// or nil if stack must not be captured.
+func (l *Logger) eventStack(level Level, msg interface{}) Stack {
+	if !l.captureStack {
+		return nil
+	}
+
+	if err, ok := msg.(error); ok {
+		if stack := errorStack(err); stack != nil {
+			return stack
+		}
+
+		return callerStack()
+	}
+
+	if level.Gte(l.stackLevel) {
+		return callerStack()
+	}
+
+	return nil
+}
+
+// callerStack returns goroutine stack trace starting from the first frame
+// outside of this package and skipped functions.
+func callerStack() Stack {
+	var pcs [maxStackDepth]uintptr
+
+	n := runtime.Callers(2, pcs[:])
+	stack := framesStack(pcs[:n])
+
+	for i, frame := range stack {
+		if !isSkipped(frame.Function) {
+			return stack[i:]
+		}
+	}
+
+	return nil
+}
+
+// errorStack returns stack trace carried by the innermost error in chain
+// of given error or nil if there is no such error.
+func errorStack(err error) Stack {
+	var stack Stack
+
+	walkErrors(err, func(err error) {
+		if pcs := errorCallers(err); len(pcs) > 0 {
+			stack = framesStack(pcs)
+		}
+	})
+
+	return stack
+}
+
+// walkErrors calls fn for given error and every error it wraps,
+// outer errors first.
+func walkErrors(err error, fn func(error)) {
+	// Methods of typed nil errors may dereference receiver.
+	for err != nil && !isNil(reflect.ValueOf(err)) {
+		fn(err)
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range multi.Unwrap() {
+				walkErrors(wrapped, fn)
+			}
+
+			return
+		}
+
+		err = errors.Unwrap(err)
+	}
+}
+
+// errorCallers returns program counters of stack trace carried by error.
+func errorCallers(err error) []uintptr {
+	if isNil(reflect.ValueOf(err)) {
+		return nil
+	}
+
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return e.Callers()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	out := method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+
+	return pcs
+}
+
+// framesStack converts program counters to stack trace.
+func framesStack(pcs []uintptr) Stack {
+	frames := runtime.CallersFrames(pcs)
+
+	var stack Stack
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+
+		if !more {
+			return stack
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+)
+
+// callersError carries stack trace the way github.com/go-errors/errors does.
+type callersError struct {
+	pcs []uintptr
+}
+
+func newCallersError() *callersError {
+	pcs := make([]uintptr, 32)
+	return &callersError{pcs: pcs[:runtime.Callers(1, pcs)]}
+}
+
+func (e *callersError) Error() string      { return "callers error" }
+func (e *callersError) Callers() []uintptr { return e.pcs }
+
+// traceFrame and stackTrace mimic types of github.com/pkg/errors.
+type (
+	traceFrame uintptr
+	stackTrace []traceFrame
+)
+
+type stackTraceError struct {
+	trace stackTrace
+}
+
+func newStackTraceError() *stackTraceError {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	trace := make(stackTrace, len(pcs))
+	for i, pc := range pcs {
+		trace[i] = traceFrame(pc)
+	}
+
+	return &stackTraceError{trace: trace}
+}
+
+func (e *stackTraceError) Error() string          { return "stack trace error" }
+func (e *stackTraceError) StackTrace() stackTrace { return e.trace }
+
+func TestCaptureStack(t *testing.T) {
+	ctx := context.Background()
+
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}, log.CaptureStack(log.LevelImportant))
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	t.Run("important event", func(t *testing.T) {
+		logger.Important(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, file, loggedEvent.Stack[0].File)
+		assert.Equal(t, line-1, loggedEvent.Stack[0].Line)
+		assert.Equal(t, "github.com/tomakado/logo/log_test.TestCaptureStack.func2", loggedEvent.Stack[0].Function)
+	})
+
+	t.Run("verbose event", func(t *testing.T) {
+		logger.Verbose(ctx, "hello")
+		assert.Nil(t, loggedEvent.Stack)
+	})
+
+	t.Run("verbose error", func(t *testing.T) {
+		logger.Verbose(ctx, errors.New("oops"))
+		_, _, line, _ := runtime.Caller(0)
+
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, line-1, loggedEvent.Stack[0].Line)
+	})
+
+	t.Run("error carrying callers", func(t *testing.T) {
+		err := newCallersError()
+		logger.Verbose(ctx, fmt.Errorf("wrapped: %w", err))
+
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, "github.com/tomakado/logo/log_test.newCallersError", loggedEvent.Stack[0].Function)
+	})
+
+	t.Run("error carrying stack trace", func(t *testing.T) {
+		err := newStackTraceError()
+		logger.Important(ctx, fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", err)))
+
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, "github.com/tomakado/logo/log_test.newStackTraceError", loggedEvent.Stack[0].Function)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		var nilErr *callersError
+
+		logger.Important(ctx, nilErr)
+		_, _, line, _ := runtime.Caller(0)
+
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, line-1, loggedEvent.Stack[0].Line)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{})
+		logger.PostHook(func(_ context.Context, e *log.Event) {
+			loggedEvent = e
+		})
+
+		logger.Important(ctx, errors.New("oops"))
+		assert.Nil(t, loggedEvent.Stack)
+	})
+}
+
+func TestCaptureStack_LevelCheck(t *testing.T) {
+	ctx := context.Background()
+	err := errors.New("oops")
+
+	var preHookStack log.Stack
+
+	logger := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{}, log.CaptureStack(log.LevelVerbose))
+	logger.PreHook(func(_ context.Context, e *log.Event) {
+		preHookStack = e.Stack
+	})
+
+	plain := log.NewLogger(log.LevelImportant, ioutil.Discard, &log.JSONFormatter{})
+
+	dropped := testing.AllocsPerRun(100, func() {
+		logger.Verbose(ctx, err)
+	})
+	droppedPlain := testing.AllocsPerRun(100, func() {
+		plain.Verbose(ctx, err)
+	})
+
+	assert.Equal(t, droppedPlain, dropped, "stack must not be captured for dropped events")
+	assert.Nil(t, preHookStack)
+
+	t.Run("async", func(t *testing.T) {
+		var loggedEvent *log.Event
+
+		logger := log.NewLogger(
+			log.LevelImportant, ioutil.Discard, &log.JSONFormatter{},
+			log.CaptureStack(log.LevelImportant), log.Async(10, log.OverflowBlock),
+		)
+		logger.PostHook(func(_ context.Context, e *log.Event) {
+			loggedEvent = e
+		})
+
+		logger.Verbose(ctx, err)
+		logger.Important(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		require.NoError(t, logger.Close(ctx))
+		require.NotEmpty(t, loggedEvent.Stack)
+		assert.Equal(t, file, loggedEvent.Stack[0].File)
+		assert.Equal(t, line-1, loggedEvent.Stack[0].Line)
+	})
+}
+
+func TestCaptureStack_Formatters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger := log.NewLogger(log.LevelVerbose, &buf, &log.JSONFormatter{}, log.CaptureStack(log.LevelImportant))
+		logger.Important(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		var decoded struct {
+			Stack []map[string]interface{} `json:"stack"`
+		}
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		require.NotEmpty(t, decoded.Stack)
+		assert.Equal(t, map[string]interface{}{
+			"function": "github.com/tomakado/logo/log_test.TestCaptureStack_Formatters.func1",
+			"file":     file,
+			"line":     float64(line - 1),
+		}, decoded.Stack[0])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger := log.NewLogger(log.LevelVerbose, &buf, log.SimpleTextFormatter, log.CaptureStack(log.LevelImportant))
+		logger.Important(ctx, "hello")
+		_, file, line, _ := runtime.Caller(0)
+
+		lines := strings.Split(buf.String(), "\n")
+		require.True(t, len(lines) > 3)
+		assert.True(t, strings.HasSuffix(lines[0], ": hello"))
+		assert.Equal(t, "\tgithub.com/tomakado/logo/log_test.TestCaptureStack_Formatters.func2", lines[1])
+		assert.Equal(t, "\t\t"+file+":"+strconv.Itoa(line-1), lines[2])
+	})
+}
+
+func TestStack_String(t *testing.T) {
+	stack := log.Stack{
+		{Function: "main.foo", File: "/app/main.go", Line: 10},
+		{Function: "main.main", File: "/app/main.go", Line: 3},
+	}
+
+	assert.Equal(t, "\tmain.foo\n\t\t/app/main.go:10\n\tmain.main\n\t\t/app/main.go:3", stack.String())
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FlattenExtra calls fn for every key-value pair of extra in order of keys.
+// Nested maps are flattened to keys joined with given separator.
+func FlattenExtra(extra map[string]interface{}, sep string, fn func(key string, value interface{})) {
+	flattenExtra("", extra, sep, fn)
+}
+
+// flattenExtra calls fn for pairs of extra with keys prefixed with given one.
+func flattenExtra(prefix string, extra map[string]interface{}, sep string, fn func(key string, value interface{})) {
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := prefix + k
+
+		switch v := extra[k].(type) {
+		case Extra:
+			flattenExtra(key+sep, v, sep, fn)
+		case map[string]interface{}:
+			flattenExtra(key+sep, v, sep, fn)
+		default:
+			fn(key, v)
+		}
+	}
+}
+
+// TextValue converts value of message or extra field to string used by
+// text formatters. Nil, including nil pointers implementing error or
+// fmt.Stringer, becomes "null", time is formatted as RFC 3339.
+func TextValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case error:
+		if isNil(reflect.ValueOf(v)) {
+			return "null"
+		}
+
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		if isNil(reflect.ValueOf(v)) {
+			return "null"
+		}
+
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,58 @@
+package log_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+)
+
+func TestFlattenExtra(t *testing.T) {
+	extra := log.Extra{
+		"user":    log.Extra{"id": 42, "name": "jon"},
+		"request": map[string]interface{}{"path": "/", "headers": log.Extra{"host": "example.com"}},
+		"a":       1,
+	}
+
+	var keys []string
+
+	var values []interface{}
+
+	log.FlattenExtra(extra, "_", func(key string, value interface{}) {
+		keys = append(keys, key)
+		values = append(values, value)
+	})
+
+	assert.Equal(t, []string{"a", "request_headers_host", "request_path", "user_id", "user_name"}, keys)
+	assert.Equal(t, []interface{}{1, "example.com", "/", 42, "jon"}, values)
+}
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+type nilStringer struct{ s string }
+
+func (s *nilStringer) String() string { return s.s }
+
+func TestTextValue(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"text", "text"},
+		{errors.New("oops"), "oops"},
+		{time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC), "2021-05-01T12:00:00Z"},
+		{time.Second, "1s"},
+		{42, "42"},
+		{(*nilError)(nil), "null"},
+		{(*nilStringer)(nil), "null"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, log.TextValue(tc.value))
+	}
+}
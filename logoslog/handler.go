@@ -0,0 +1,235 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Package logoslog implements log/slog Handler backed by logo Logger,
+so code using standard structured logging API writes through logo
+sinks, formatters and hooks:
+
+	logger := log.NewLogger(log.LevelVerbose, os.Stderr, &log.JSONFormatter{})
+	slog.SetDefault(slog.New(logoslog.NewHandler(logger, nil)))
+
+	slog.Info("hello", "user_id", 42)
+*/
+package logoslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+
+	"github.com/tomakado/logo/log"
+)
+
+// DefaultLevels maps slog levels to logo levels by default.
+var DefaultLevels = map[slog.Level]log.Level{
+	slog.LevelDebug: log.LevelVerbose,
+	slog.LevelInfo:  log.LevelVerbose,
+	slog.LevelWarn:  log.LevelImportant,
+	slog.LevelError: log.LevelImportant,
+}
+
+// Options configures Handler.
+type Options struct {
+	// Levels maps slog levels to logo levels. Record level is mapped to logo
+	// level of the greatest slog level in table not exceeding it, levels lower
+	// than all slog levels in table are mapped to logo level of the lowest one.
+	// DefaultLevels is used if table is empty.
+	Levels map[slog.Level]log.Level
+}
+
+// Handler is a slog.Handler forwarding records to logo Logger. Record attrs
+// become event's extra, groups become nested extra. Attrs added with WithAttrs
+// outside of any group are bound to logger with log.Logger.With.
+type Handler struct {
+	logger *log.Logger
+	levels []levelMapping
+	steps  []step
+}
+
+// levelMapping maps slog levels starting from given one to logo level.
+type levelMapping struct {
+	from slog.Level
+	to   log.Level
+}
+
+// step is either a group opened with WithGroup or attrs added with WithAttrs
+// inside of a group.
+type step struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler creates a new instance of Handler writing records to given logger.
+// Options may be nil.
+func NewHandler(logger *log.Logger, opts *Options) *Handler {
+	table := DefaultLevels
+	if opts != nil && len(opts.Levels) > 0 {
+		table = opts.Levels
+	}
+
+	levels := make([]levelMapping, 0, len(table))
+	for from, to := range table {
+		levels = append(levels, levelMapping{from: from, to: to})
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].from < levels[j].from
+	})
+
+	return &Handler{
+		logger: logger,
+		levels: levels,
+	}
+}
+
+// Enabled reports whether records with given level pass logger level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.level(level).Gte(h.logger.Level())
+}
+
+// Handle converts record to logo event and writes it to logger.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	extra := log.Extra{}
+	target := extra
+	groups := make([]string, 0, len(h.steps))
+
+	for _, s := range h.steps {
+		if s.attrs == nil {
+			group := log.Extra{}
+			target[s.group] = group
+			target = group
+			groups = append(groups, s.group)
+
+			continue
+		}
+
+		for _, a := range s.attrs {
+			addAttr(target, a)
+		}
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(target, a)
+		return true
+	})
+
+	pruneGroups(extra, groups)
+
+	event := log.Event{
+		Time:    r.Time,
+		Level:   h.level(r.Level),
+		Message: r.Message,
+		Extra:   extra,
+	}
+
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		event.Caller = &log.Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		}
+	}
+
+	h.logger.WriteEvent(ctx, event)
+
+	return nil
+}
+
+// WithAttrs returns a handler with given attrs bound to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	child := *h
+
+	if len(h.steps) == 0 {
+		extra := log.Extra{}
+		for _, a := range attrs {
+			addAttr(extra, a)
+		}
+
+		child.logger = h.logger.With(extra)
+
+		return &child
+	}
+
+	child.steps = append(h.steps[:len(h.steps):len(h.steps)], step{attrs: attrs})
+
+	return &child
+}
+
+// WithGroup returns a handler putting subsequent attrs into group with given name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.steps = append(h.steps[:len(h.steps):len(h.steps)], step{group: name})
+
+	return &child
+}
+
+// level maps slog level to logo level.
+func (h *Handler) level(level slog.Level) log.Level {
+	mapped := h.levels[0].to
+
+	for _, m := range h.levels {
+		if level < m.from {
+			break
+		}
+
+		mapped = m.to
+	}
+
+	return mapped
+}
+
+// addAttr puts resolved attr into extra. Empty attrs and groups are ignored,
+// groups with empty key are inlined.
+func addAttr(extra log.Extra, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		extra[a.Key] = a.Value.Any()
+		return
+	}
+
+	if a.Key == "" {
+		for _, ga := range a.Value.Group() {
+			addAttr(extra, ga)
+		}
+
+		return
+	}
+
+	group := log.Extra{}
+	for _, ga := range a.Value.Group() {
+		addAttr(group, ga)
+	}
+
+	if len(group) > 0 {
+		extra[a.Key] = group
+	}
+}
+
+// pruneGroups removes groups left empty along given path of group names.
+func pruneGroups(extra log.Extra, groups []string) bool {
+	if len(groups) == 0 {
+		return len(extra) == 0
+	}
+
+	group, _ := extra[groups[0]].(log.Extra)
+	if pruneGroups(group, groups[1:]) {
+		delete(extra, groups[0])
+	}
+
+	return len(extra) == 0
+}
@@ -0,0 +1,138 @@
+//go:build go1.21
+// +build go1.21
+
+package logoslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/logoslog"
+)
+
+func TestHandler_Conformance(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := log.NewLogger(log.LevelVerbose, &buf, &log.JSONFormatter{})
+
+	results := func() []map[string]interface{} {
+		var records []map[string]interface{}
+
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var event struct {
+				Level   string                 `json:"level"`
+				Time    time.Time              `json:"time"`
+				Message string                 `json:"message"`
+				Extra   map[string]interface{} `json:"extra"`
+			}
+
+			require.NoError(t, json.Unmarshal([]byte(line), &event))
+
+			record := map[string]interface{}{
+				slog.LevelKey:   event.Level,
+				slog.MessageKey: event.Message,
+			}
+
+			if !event.Time.IsZero() {
+				record[slog.TimeKey] = event.Time
+			}
+
+			for k, v := range event.Extra {
+				record[k] = v
+			}
+
+			records = append(records, record)
+		}
+
+		return records
+	}
+
+	require.NoError(t, slogtest.TestHandler(logoslog.NewHandler(logger, nil), results))
+}
+
+func TestHandler(t *testing.T) {
+	var loggedEvent *log.Event
+
+	logger := log.NewLogger(log.LevelImportant, &bytes.Buffer{}, &log.JSONFormatter{}, log.ReportCaller())
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		loggedEvent = e
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		handler := logoslog.NewHandler(logger, nil)
+		ctx := context.Background()
+
+		assert.False(t, handler.Enabled(ctx, slog.LevelDebug))
+		assert.False(t, handler.Enabled(ctx, slog.LevelInfo))
+		assert.True(t, handler.Enabled(ctx, slog.LevelWarn))
+		assert.True(t, handler.Enabled(ctx, slog.LevelError+4))
+
+		slog.New(handler).Error("oops")
+		assert.Equal(t, log.LevelImportant, loggedEvent.Level)
+	})
+
+	t.Run("custom levels", func(t *testing.T) {
+		levelCritical := log.NewLevel(30, "CRITICAL")
+		handler := logoslog.NewHandler(logger, &logoslog.Options{
+			Levels: map[slog.Level]log.Level{
+				slog.LevelInfo:  log.LevelVerbose,
+				slog.LevelError: log.LevelImportant,
+				slog.Level(12):  levelCritical,
+			},
+		})
+
+		ctx := context.Background()
+
+		assert.False(t, handler.Enabled(ctx, slog.LevelDebug))
+		assert.False(t, handler.Enabled(ctx, slog.LevelWarn))
+		assert.True(t, handler.Enabled(ctx, slog.LevelError))
+
+		slog.New(handler).Log(ctx, slog.Level(16), "hello")
+		assert.Equal(t, levelCritical, loggedEvent.Level)
+	})
+
+	t.Run("attrs and groups", func(t *testing.T) {
+		slogger := slog.New(logoslog.NewHandler(logger, nil)).
+			With("service", "api").
+			WithGroup("request").
+			With("id", "abc")
+
+		slogger.Error("failed", "status", 500, slog.Group("user", "id", 42))
+
+		assert.Equal(t, "failed", loggedEvent.Message)
+		assert.Equal(t, log.Extra{
+			"service": "api",
+			"request": log.Extra{
+				"id":     "abc",
+				"status": int64(500),
+				"user":   log.Extra{"id": int64(42)},
+			},
+		}, loggedEvent.Extra)
+	})
+
+	t.Run("context extra", func(t *testing.T) {
+		ctx := log.WithExtra(context.Background(), log.Extra{"request_id": "abc"})
+
+		slog.New(logoslog.NewHandler(logger, nil)).ErrorContext(ctx, "failed", "status", 500)
+		assert.Equal(t, log.Extra{"request_id": "abc", "status": int64(500)}, loggedEvent.Extra)
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		slog.New(logoslog.NewHandler(logger, nil)).Error("failed")
+		_, file, line, _ := runtime.Caller(0)
+
+		require.NotNil(t, loggedEvent.Caller)
+		assert.Equal(t, file, loggedEvent.Caller.File)
+		assert.Equal(t, line-1, loggedEvent.Caller.Line)
+	})
+}
@@ -0,0 +1,152 @@
+/*
+Package stdlog bridges standard library log package into logo, so
+output of third-party libraries using log.Printf goes through logo
+formatters, sinks and hooks:
+
+	logger := log.NewLogger(log.LevelVerbose, os.Stderr, &log.JSONFormatter{})
+
+	restore := stdlog.Redirect(logger, log.LevelVerbose, "stdlog")
+	defer restore()
+*/
+package stdlog
+
+import (
+	"context"
+	golog "log"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomakado/logo/log"
+)
+
+// Lengths of headers written by standard logger.
+const (
+	dateLen             = len("2006/01/02 ")
+	timeLen             = len("15:04:05 ")
+	timeMicrosecondsLen = len("15:04:05.000000 ")
+)
+
+func init() {
+	// Functions between code calling standard logger and Writer.
+	log.SkipCallers(
+		"log.",
+		"log/internal.",
+		"log/slog.",
+		func() string {
+			name := runtime.FuncForPC(reflect.ValueOf(New).Pointer()).Name()
+			return name[:strings.LastIndex(name, ".")+1]
+		}(),
+	)
+}
+
+// Writer is an io.Writer parsing entries written by standard logger
+// and writing them to logo Logger. Prefix, date, time and file written
+// by standard logger are stripped according to its prefix and flags.
+// File and line, if present, become event's caller, otherwise logger
+// reporting caller finds the code which called standard logger. Every
+// event has source extra field.
+type Writer struct {
+	logger *log.Logger
+	level  log.Level
+	source string
+	prefix string
+	flags  int
+}
+
+// NewWriter creates a new instance of Writer writing events with given level
+// and source to given logger. Prefix and flags are those of standard logger
+// writing to the writer.
+func NewWriter(logger *log.Logger, level log.Level, source, prefix string, flags int) *Writer {
+	return &Writer{
+		logger: logger,
+		level:  level,
+		source: source,
+		prefix: prefix,
+		flags:  flags,
+	}
+}
+
+// New creates a new instance of standard logger writing events with given
+// level and source to given logger.
+func New(logger *log.Logger, level log.Level, source string) *golog.Logger {
+	return golog.New(NewWriter(logger, level, source, "", 0), "", 0)
+}
+
+// Redirect makes standard library default logger write events with given
+// level and source to given logger. Current prefix and flags of default
+// logger are kept and must not be changed until returned function restoring
+// previous output is called.
+func Redirect(logger *log.Logger, level log.Level, source string) func() {
+	output := golog.Writer()
+
+	golog.SetOutput(NewWriter(logger, level, source, golog.Prefix(), golog.Flags()))
+
+	return func() {
+		golog.SetOutput(output)
+	}
+}
+
+// Write parses p as a single entry of standard logger and writes it to logger.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.logger.Level().Gt(w.level) {
+		return len(p), nil
+	}
+
+	entry := strings.TrimSuffix(string(p), "\n")
+
+	if w.flags&golog.Lmsgprefix == 0 {
+		entry = strings.TrimPrefix(entry, w.prefix)
+	}
+
+	if w.flags&golog.Ldate != 0 && len(entry) >= dateLen {
+		entry = entry[dateLen:]
+	}
+
+	if w.flags&golog.Lmicroseconds != 0 && len(entry) >= timeMicrosecondsLen {
+		entry = entry[timeMicrosecondsLen:]
+	} else if w.flags&golog.Ltime != 0 && len(entry) >= timeLen {
+		entry = entry[timeLen:]
+	}
+
+	event := log.Event{
+		Time:  time.Now(),
+		Level: w.level,
+		Extra: log.Extra{"source": w.source},
+	}
+
+	if w.flags&(golog.Lshortfile|golog.Llongfile) != 0 {
+		event.Caller, entry = parseCaller(entry)
+	}
+
+	if w.flags&golog.Lmsgprefix != 0 {
+		entry = strings.TrimPrefix(entry, w.prefix)
+	}
+
+	event.Message = entry
+	w.logger.WriteEvent(context.Background(), event)
+
+	return len(p), nil
+}
+
+// parseCaller parses "file:line: " header of entry.
+func parseCaller(entry string) (*log.Frame, string) {
+	end := strings.Index(entry, ": ")
+	if end < 0 {
+		return nil, entry
+	}
+
+	sep := strings.LastIndex(entry[:end], ":")
+	if sep < 0 {
+		return nil, entry
+	}
+
+	line, err := strconv.Atoi(entry[sep+1 : end])
+	if err != nil {
+		return nil, entry
+	}
+
+	return &log.Frame{File: entry[:sep], Line: line}, entry[end+2:]
+}
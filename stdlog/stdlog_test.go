@@ -0,0 +1,137 @@
+package stdlog_test
+
+import (
+	"context"
+	"io/ioutil"
+	golog "log"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/stdlog"
+)
+
+func newTestLogger(opts ...log.Option) (*log.Logger, *[]log.Event) {
+	var events []log.Event
+
+	logger := log.NewLogger(log.LevelVerbose, ioutil.Discard, &log.JSONFormatter{}, opts...)
+	logger.PostHook(func(_ context.Context, e *log.Event) {
+		events = append(events, *e)
+	})
+
+	return logger, &events
+}
+
+func TestWriter(t *testing.T) {
+	cases := []struct {
+		name   string
+		prefix string
+		flags  int
+	}{
+		{name: "no flags", flags: 0},
+		{name: "standard flags", flags: golog.LstdFlags},
+		{name: "prefix", prefix: "[lib] ", flags: golog.LstdFlags},
+		{name: "message prefix", prefix: "[lib] ", flags: golog.LstdFlags | golog.Lmsgprefix},
+		{name: "microseconds", prefix: "lib: ", flags: golog.Ldate | golog.Lmicroseconds | golog.LUTC},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			logger, events := newTestLogger()
+
+			std := golog.New(stdlog.NewWriter(logger, log.LevelImportant, "lib", tc.prefix, tc.flags), tc.prefix, tc.flags)
+			std.Printf("hello, %s", "Jon Snow")
+
+			require.Len(t, *events, 1)
+			assert.Equal(t, log.LevelImportant, (*events)[0].Level)
+			assert.Equal(t, "hello, Jon Snow", (*events)[0].Message)
+			assert.Equal(t, log.Extra{"source": "lib"}, (*events)[0].Extra)
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		logger, events := newTestLogger(log.ReportCaller())
+
+		flags := golog.LstdFlags | golog.Lshortfile
+		std := golog.New(stdlog.NewWriter(logger, log.LevelVerbose, "lib", "", flags), "", flags)
+
+		std.Print("hello: world")
+		_, file, line, _ := runtime.Caller(0)
+
+		require.Len(t, *events, 1)
+		assert.Equal(t, "hello: world", (*events)[0].Message)
+		assert.Equal(t, &log.Frame{File: filepath.Base(file), Line: line - 1}, (*events)[0].Caller)
+	})
+
+	t.Run("caller without file flag", func(t *testing.T) {
+		logger, events := newTestLogger(log.ReportCaller())
+
+		std := golog.New(stdlog.NewWriter(logger, log.LevelVerbose, "lib", "", golog.LstdFlags), "", golog.LstdFlags)
+
+		std.Print("hello")
+		pc, file, line, _ := runtime.Caller(0)
+
+		require.Len(t, *events, 1)
+		assert.Equal(t, &log.Frame{
+			Function: runtime.FuncForPC(pc).Name(),
+			File:     file,
+			Line:     line - 1,
+		}, (*events)[0].Caller)
+	})
+
+	t.Run("below logger level", func(t *testing.T) {
+		logger, events := newTestLogger(log.ReportCaller())
+		logger.SetLevel(log.LevelImportant)
+
+		std := stdlog.New(logger, log.LevelVerbose, "lib")
+		std.Print("hello")
+
+		assert.Empty(t, *events)
+	})
+
+	t.Run("caller not reported", func(t *testing.T) {
+		logger, events := newTestLogger()
+
+		std := stdlog.New(logger, log.LevelVerbose, "lib")
+		std.Print("hello")
+
+		require.Len(t, *events, 1)
+		assert.Nil(t, (*events)[0].Caller)
+	})
+
+	t.Run("multi-line message", func(t *testing.T) {
+		logger, events := newTestLogger()
+
+		std := stdlog.New(logger, log.LevelVerbose, "lib")
+		std.Print("hello\nworld\n")
+
+		require.Len(t, *events, 1)
+		assert.Equal(t, "hello\nworld", (*events)[0].Message)
+	})
+}
+
+func TestRedirect(t *testing.T) {
+	logger, events := newTestLogger(log.ReportCaller())
+
+	output := golog.Writer()
+
+	restore := stdlog.Redirect(logger, log.LevelVerbose, "stdlog")
+	golog.Println("hello")
+	_, file, line, _ := runtime.Caller(0)
+	restore()
+
+	assert.Equal(t, output, golog.Writer())
+
+	require.Len(t, *events, 1)
+	assert.Equal(t, "hello", (*events)[0].Message)
+	assert.Equal(t, log.Extra{"source": "stdlog"}, (*events)[0].Extra)
+
+	require.NotNil(t, (*events)[0].Caller)
+	assert.Equal(t, file, (*events)[0].Caller.File)
+	assert.Equal(t, line-1, (*events)[0].Caller.Line)
+}
@@ -0,0 +1,584 @@
+// Package httpbatch provides writer collecting formatted events into
+// batches and posting them to HTTP endpoint:
+//
+//	w, err := httpbatch.New("https://logs.example.com/ingest", httpbatch.Header("Authorization", "Bearer "+token))
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer w.Close()
+//
+//	logger := log.NewLogger(log.LevelVerbose, w, &log.JSONFormatter{})
+package httpbatch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned on writing to closed Writer.
+var ErrClosed = errors.New("httpbatch: writer is closed")
+
+// Default settings of Writer.
+const (
+	DefaultMaxEvents    = 100
+	DefaultMaxBytes     = 1 << 20
+	DefaultInterval     = time.Second
+	DefaultMaxPending   = 16
+	DefaultMaxRetries   = 5
+	DefaultMinBackoff   = 500 * time.Millisecond
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultDrainTimeout = 10 * time.Second
+)
+
+// Option configures Writer.
+type Option func(*Writer)
+
+// MaxEvents sets number of events which triggers sending of batch,
+// DefaultMaxEvents by default.
+func MaxEvents(n int) Option {
+	return func(w *Writer) {
+		w.maxEvents = n
+	}
+}
+
+// MaxBytes sets total size of events which triggers sending of batch,
+// DefaultMaxBytes by default.
+func MaxBytes(n int) Option {
+	return func(w *Writer) {
+		w.maxBytes = n
+	}
+}
+
+// Interval sets how often incomplete batch is sent, DefaultInterval
+// by default.
+func Interval(interval time.Duration) Option {
+	return func(w *Writer) {
+		w.interval = interval
+	}
+}
+
+// MaxPending limits number of batches waiting to be sent, newer batches
+// are dropped when limit is reached. DefaultMaxPending is used by default.
+func MaxPending(n int) Option {
+	return func(w *Writer) {
+		w.maxPending = n
+	}
+}
+
+// JSONArray makes writer send batch as JSON array of events instead of
+// newline-delimited JSON. Events must be formatted as JSON.
+func JSONArray() Option {
+	return func(w *Writer) {
+		w.array = true
+	}
+}
+
+// Gzip makes writer compress request bodies.
+func Gzip() Option {
+	return func(w *Writer) {
+		w.gzip = true
+	}
+}
+
+// Header adds header to every request, e.g. for authorization.
+func Header(key, value string) Option {
+	return func(w *Writer) {
+		w.header.Add(key, value)
+	}
+}
+
+// Client sets HTTP client used to send requests, http.DefaultClient
+// is used by default.
+func Client(client *http.Client) Option {
+	return func(w *Writer) {
+		w.client = client
+	}
+}
+
+// Retry sets number of retries of batch after network errors and 5xx or
+// 429 responses and delays between them. Delay is doubled after every
+// attempt unless server sets Retry-After header. DefaultMaxRetries,
+// DefaultMinBackoff and DefaultMaxBackoff are used by default.
+func Retry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(w *Writer) {
+		w.maxRetries = maxRetries
+		w.minBackoff = minBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// DrainTimeout limits time Close spends on sending remaining batches,
+// DefaultDrainTimeout by default.
+func DrainTimeout(timeout time.Duration) Option {
+	return func(w *Writer) {
+		w.drainTimeout = timeout
+	}
+}
+
+// OnError sets handler of errors of sending batches, such errors are
+// ignored by default. Batch is dropped after handler is called.
+func OnError(handler func(err error)) Option {
+	return func(w *Writer) {
+		w.onError = handler
+	}
+}
+
+// StatusError is returned when endpoint responds with unexpected status.
+type StatusError struct {
+	StatusCode int
+}
+
+// Error returns text of error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpbatch: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Stats holds counters of Writer.
+type Stats struct {
+	// SentEvents is a number of events delivered to endpoint.
+	SentEvents uint64
+	// SentBatches is a number of requests accepted by endpoint.
+	SentBatches uint64
+	// DroppedEvents is a number of events dropped because too many
+	// batches were pending or batch couldn't be delivered.
+	DroppedEvents uint64
+	// Retries is a number of repeated requests.
+	Retries uint64
+}
+
+// Writer is io.WriteCloser collecting events into batches and posting
+// them to HTTP endpoint in background, every Write is a single event
+// with trailing newline trimmed. Write never blocks on network.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	url          string
+	client       *http.Client
+	header       http.Header
+	maxEvents    int
+	maxBytes     int
+	interval     time.Duration
+	maxPending   int
+	array        bool
+	gzip         bool
+	maxRetries   int
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	drainTimeout time.Duration
+	onError      func(err error)
+
+	mx     sync.Mutex
+	batch  [][]byte
+	size   int
+	closed bool
+	stats  Stats
+
+	batches chan [][]byte
+	flushes chan chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// New creates a new instance of Writer posting batches to given URL.
+func New(endpoint string, opts ...Option) (*Writer, error) {
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		url:          endpoint,
+		client:       http.DefaultClient,
+		header:       make(http.Header),
+		maxEvents:    DefaultMaxEvents,
+		maxBytes:     DefaultMaxBytes,
+		interval:     DefaultInterval,
+		maxPending:   DefaultMaxPending,
+		maxRetries:   DefaultMaxRetries,
+		minBackoff:   DefaultMinBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		drainTimeout: DefaultDrainTimeout,
+		onError:      func(error) {},
+		flushes:      make(chan chan struct{}),
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+
+	w.batches = make(chan [][]byte, w.maxPending)
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	go w.run()
+
+	return w, nil
+}
+
+// validate checks settings set by options.
+func (w *Writer) validate() error {
+	switch {
+	case w.maxEvents <= 0:
+		return fmt.Errorf("httpbatch: max events must be positive, got %d", w.maxEvents)
+	case w.maxBytes <= 0:
+		return fmt.Errorf("httpbatch: max bytes must be positive, got %d", w.maxBytes)
+	case w.interval <= 0:
+		return fmt.Errorf("httpbatch: interval must be positive, got %s", w.interval)
+	case w.maxPending < 0:
+		return fmt.Errorf("httpbatch: max pending must not be negative, got %d", w.maxPending)
+	case w.maxRetries < 0:
+		return fmt.Errorf("httpbatch: max retries must not be negative, got %d", w.maxRetries)
+	case w.minBackoff < 0 || w.maxBackoff < w.minBackoff:
+		return fmt.Errorf("httpbatch: invalid backoff range [%s, %s]", w.minBackoff, w.maxBackoff)
+	case w.client == nil:
+		return errors.New("httpbatch: client must not be nil")
+	}
+
+	return nil
+}
+
+// Write adds given event to current batch.
+func (w *Writer) Write(p []byte) (int, error) {
+	event := bytes.TrimRight(p, "\n")
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if len(event) == 0 {
+		return len(p), nil
+	}
+
+	w.batch = append(w.batch, append([]byte(nil), event...))
+	w.size += len(event)
+
+	if len(w.batch) >= w.maxEvents || w.size >= w.maxBytes {
+		select {
+		case w.batches <- w.batch:
+		default:
+			w.stats.DroppedEvents += uint64(len(w.batch))
+		}
+
+		w.batch, w.size = nil, 0
+	}
+
+	return len(p), nil
+}
+
+// Flush sends current batch and all pending ones and waits until they
+// are delivered or dropped, or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	select {
+	case w.flushes <- flushed:
+	case <-w.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns current values of writer's counters.
+func (w *Writer) Stats() Stats {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	return w.stats
+}
+
+// Close stops accepting events and sends remaining batches, waiting for
+// them at most drain timeout.
+func (w *Writer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.drainTimeout)
+	defer cancel()
+
+	return w.Shutdown(ctx)
+}
+
+// Shutdown stops accepting events and sends remaining batches until
+// ctx is done, then batches which weren't delivered are dropped.
+func (w *Writer) Shutdown(ctx context.Context) error {
+	w.mx.Lock()
+
+	if w.closed {
+		w.mx.Unlock()
+		return ErrClosed
+	}
+
+	w.closed = true
+
+	w.mx.Unlock()
+
+	close(w.closing)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+
+		return ctx.Err()
+	}
+}
+
+// run sends batches until writer is closed.
+func (w *Writer) run() {
+	defer close(w.done)
+	defer w.cancel()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-w.batches:
+			w.send(batch)
+		case <-ticker.C:
+			w.sendAll()
+		case flushed := <-w.flushes:
+			w.sendAll()
+			close(flushed)
+		case <-w.closing:
+			w.sendAll()
+			return
+		}
+	}
+}
+
+// sendAll sends pending batches and then current one.
+func (w *Writer) sendAll() {
+	for drained := false; !drained; {
+		select {
+		case batch := <-w.batches:
+			w.send(batch)
+		default:
+			drained = true
+		}
+	}
+
+	w.mx.Lock()
+	batch := w.batch
+	w.batch, w.size = nil, 0
+	w.mx.Unlock()
+
+	if len(batch) > 0 {
+		w.send(batch)
+	}
+}
+
+// send posts batch retrying it if needed.
+func (w *Writer) send(batch [][]byte) {
+	body, err := w.encode(batch)
+	if err != nil {
+		w.drop(batch, fmt.Errorf("httpbatch: encode batch: %w", err))
+		return
+	}
+
+	backoff := w.minBackoff
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := w.post(body)
+		if err == nil {
+			w.mx.Lock()
+			w.stats.SentEvents += uint64(len(batch))
+			w.stats.SentBatches++
+			w.mx.Unlock()
+
+			return
+		}
+
+		if !isRetryable(err) || attempt >= w.maxRetries || w.ctx.Err() != nil {
+			w.drop(batch, err)
+			return
+		}
+
+		delay := backoff
+		if retryAfter >= 0 {
+			delay = retryAfter
+		}
+
+		if backoff *= 2; backoff > w.maxBackoff {
+			backoff = w.maxBackoff
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-w.ctx.Done():
+			timer.Stop()
+			w.drop(batch, err)
+
+			return
+		}
+
+		w.mx.Lock()
+		w.stats.Retries++
+		w.mx.Unlock()
+	}
+}
+
+// drop counts batch as dropped and reports error.
+func (w *Writer) drop(batch [][]byte, err error) {
+	w.mx.Lock()
+	w.stats.DroppedEvents += uint64(len(batch))
+	w.mx.Unlock()
+
+	w.onError(err)
+}
+
+// encode renders batch as request body.
+func (w *Writer) encode(batch [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var out io.Writer = &buf
+
+	var gz *gzip.Writer
+	if w.gzip {
+		gz = gzip.NewWriter(&buf)
+		out = gz
+	}
+
+	if w.array {
+		_, _ = io.WriteString(out, "[")
+	}
+
+	for i, event := range batch {
+		if w.array && i > 0 {
+			_, _ = io.WriteString(out, ",")
+		}
+
+		if _, err := out.Write(event); err != nil {
+			return nil, err
+		}
+
+		if !w.array {
+			_, _ = io.WriteString(out, "\n")
+		}
+	}
+
+	if w.array {
+		_, _ = io.WriteString(out, "]")
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// retryableError marks errors after which request can be repeated.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// isRetryable returns true if request can be repeated after given error.
+func isRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
+
+// post sends request with given body. Delay requested by server with
+// Retry-After header is returned along with error, it's negative if
+// header is missing.
+func (w *Writer) post(body []byte) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	req = req.WithContext(w.ctx)
+
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+
+	if w.array {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+
+	if w.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return -1, retryableError{err}
+	}
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return -1, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryAfter(resp.Header.Get("Retry-After")), retryableError{&StatusError{StatusCode: resp.StatusCode}}
+	default:
+		return -1, &StatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+// retryAfter parses value of Retry-After header, which is either number
+// of seconds or HTTP date. Negative duration is returned if value is
+// missing or invalid.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return -1
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+
+		return 0
+	}
+
+	return -1
+}
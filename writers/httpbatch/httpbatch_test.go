@@ -0,0 +1,419 @@
+package httpbatch_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/httpbatch"
+)
+
+// request is a request received by endpoint.
+type request struct {
+	header http.Header
+	body   string
+}
+
+// endpoint records requests and responds with given statuses, the last
+// status is repeated.
+type endpoint struct {
+	*httptest.Server
+	requests chan request
+
+	mx       sync.Mutex
+	statuses []int
+	headers  []http.Header
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	t.Helper()
+
+	e := &endpoint{requests: make(chan request, 100), statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(e.serve))
+
+	t.Cleanup(e.Close)
+
+	return e
+}
+
+func (e *endpoint) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		body, _ = ioutil.ReadAll(gz)
+	}
+
+	e.mx.Lock()
+
+	status := http.StatusOK
+	if len(e.statuses) > 0 {
+		status = e.statuses[0]
+		if len(e.statuses) > 1 {
+			e.statuses = e.statuses[1:]
+		}
+	}
+
+	if len(e.headers) > 0 {
+		for key, values := range e.headers[0] {
+			w.Header()[key] = values
+		}
+
+		e.headers = e.headers[1:]
+	}
+
+	e.mx.Unlock()
+
+	e.requests <- request{header: r.Header, body: string(body)}
+
+	w.WriteHeader(status)
+}
+
+// respondWith sets headers of the next responses.
+func (e *endpoint) respondWith(headers ...http.Header) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	e.headers = headers
+}
+
+// receive waits for the next request.
+func (e *endpoint) receive(t *testing.T) request {
+	t.Helper()
+
+	select {
+	case r := <-e.requests:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("request is not received")
+		return request{}
+	}
+}
+
+// expectNoRequest fails test if request arrives soon.
+func (e *endpoint) expectNoRequest(t *testing.T) {
+	t.Helper()
+
+	select {
+	case r := <-e.requests:
+		t.Fatalf("unexpected request %q", r.body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func write(t *testing.T, w *httpbatch.Writer, events ...string) {
+	t.Helper()
+
+	for _, event := range events {
+		n, err := w.Write([]byte(event))
+		require.NoError(t, err)
+		require.Equal(t, len(event), n)
+	}
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(
+		e.URL,
+		httpbatch.MaxEvents(3),
+		httpbatch.Interval(time.Hour),
+		httpbatch.Header("Authorization", "Bearer token"),
+	)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	logger := log.NewLogger(log.LevelVerbose, w, &log.JSONFormatter{})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		logger.Verbose(context.Background(), msg)
+	}
+
+	r := e.receive(t)
+	assert.Equal(t, "Bearer token", r.header.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", r.header.Get("Content-Type"))
+
+	lines := strings.Split(r.body, "\n")
+	require.Len(t, lines, 4)
+	assert.Empty(t, lines[3])
+
+	for i, expected := range []string{"one", "two", "three"} {
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &decoded))
+		assert.Equal(t, expected, decoded["message"])
+	}
+
+	e.expectNoRequest(t)
+
+	stats := w.Stats()
+	assert.Equal(t, uint64(3), stats.SentEvents)
+	assert.Equal(t, uint64(1), stats.SentBatches)
+}
+
+func TestWriter_JSONArray(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(e.URL, httpbatch.MaxEvents(2), httpbatch.JSONArray(), httpbatch.Gzip())
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, `{"n":1}`+"\n", `{"n":2}`+"\n")
+
+	r := e.receive(t)
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	assert.Equal(t, "gzip", r.header.Get("Content-Encoding"))
+	assert.Equal(t, `[{"n":1},{"n":2}]`, r.body)
+}
+
+func TestWriter_MaxBytes(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(e.URL, httpbatch.MaxBytes(10), httpbatch.Interval(time.Hour))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "aaaa\n", "bbbb\n")
+	e.expectNoRequest(t)
+
+	write(t, w, "cc\n")
+	assert.Equal(t, "aaaa\nbbbb\ncc\n", e.receive(t).body)
+}
+
+func TestWriter_Interval(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(e.URL, httpbatch.Interval(20*time.Millisecond))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "lonely\n")
+	assert.Equal(t, "lonely\n", e.receive(t).body)
+}
+
+func TestWriter_Retry(t *testing.T) {
+	e := newEndpoint(t, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK)
+	e.respondWith(http.Header{"Retry-After": {"0"}})
+
+	w, err := httpbatch.New(
+		e.URL,
+		httpbatch.MaxEvents(1),
+		httpbatch.Retry(3, time.Millisecond, 5*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "retried\n")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "retried\n", e.receive(t).body)
+	}
+
+	assert.Eventually(t, func() bool {
+		return w.Stats().SentEvents == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(2), w.Stats().Retries)
+
+	t.Run("retry after", func(t *testing.T) {
+		e := newEndpoint(t, http.StatusServiceUnavailable, http.StatusOK)
+		e.respondWith(http.Header{"Retry-After": {"1"}})
+
+		w, err := httpbatch.New(
+			e.URL,
+			httpbatch.MaxEvents(1),
+			httpbatch.Retry(1, time.Millisecond, time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		defer w.Close()
+
+		write(t, w, "later\n")
+
+		e.receive(t)
+		start := time.Now()
+		e.receive(t)
+
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(900*time.Millisecond))
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		e := newEndpoint(t, http.StatusInternalServerError)
+
+		errs := make(chan error, 1)
+
+		w, err := httpbatch.New(
+			e.URL,
+			httpbatch.MaxEvents(1),
+			httpbatch.Retry(2, time.Millisecond, time.Millisecond),
+			httpbatch.OnError(func(err error) { errs <- err }),
+		)
+		require.NoError(t, err)
+
+		defer w.Close()
+
+		write(t, w, "lost\n")
+
+		var statusErr *httpbatch.StatusError
+
+		select {
+		case err := <-errs:
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+		case <-time.After(2 * time.Second):
+			t.Fatal("error is not reported")
+		}
+
+		stats := w.Stats()
+		assert.Equal(t, uint64(1), stats.DroppedEvents)
+		assert.Equal(t, uint64(2), stats.Retries)
+	})
+}
+
+func TestWriter_NoRetry(t *testing.T) {
+	e := newEndpoint(t, http.StatusBadRequest)
+
+	errs := make(chan error, 1)
+
+	w, err := httpbatch.New(
+		e.URL,
+		httpbatch.MaxEvents(1),
+		httpbatch.OnError(func(err error) { errs <- err }),
+	)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "rejected\n")
+
+	var statusErr *httpbatch.StatusError
+
+	select {
+	case err := <-errs:
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	case <-time.After(2 * time.Second):
+		t.Fatal("error is not reported")
+	}
+
+	e.receive(t)
+	e.expectNoRequest(t)
+
+	stats := w.Stats()
+	assert.Equal(t, uint64(1), stats.DroppedEvents)
+	assert.Equal(t, uint64(0), stats.Retries)
+}
+
+func TestWriter_Flush(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(e.URL, httpbatch.Interval(time.Hour))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "flushed\n")
+	require.NoError(t, w.Flush(context.Background()))
+
+	assert.Equal(t, "flushed\n", e.receive(t).body)
+	assert.Equal(t, uint64(1), w.Stats().SentEvents)
+}
+
+func TestWriter_Close(t *testing.T) {
+	e := newEndpoint(t)
+
+	w, err := httpbatch.New(e.URL, httpbatch.MaxEvents(2), httpbatch.Interval(time.Hour))
+	require.NoError(t, err)
+
+	write(t, w, "one\n", "two\n", "three\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "one\ntwo\n", e.receive(t).body)
+	assert.Equal(t, "three\n", e.receive(t).body)
+	assert.Equal(t, uint64(3), w.Stats().SentEvents)
+
+	_, err = w.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, httpbatch.ErrClosed)
+	assert.ErrorIs(t, w.Close(), httpbatch.ErrClosed)
+	assert.ErrorIs(t, w.Flush(context.Background()), httpbatch.ErrClosed)
+
+	t.Run("drain timeout", func(t *testing.T) {
+		e := newEndpoint(t, http.StatusServiceUnavailable)
+
+		w, err := httpbatch.New(
+			e.URL,
+			httpbatch.Interval(time.Hour),
+			httpbatch.Retry(100, time.Hour, time.Hour),
+		)
+		require.NoError(t, err)
+
+		write(t, w, "stuck\n")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, w.Shutdown(ctx), context.DeadlineExceeded)
+		assert.Equal(t, uint64(1), w.Stats().DroppedEvents)
+	})
+}
+
+func TestWriter_MaxPending(t *testing.T) {
+	e := newEndpoint(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	w, err := httpbatch.New(
+		e.URL,
+		httpbatch.MaxEvents(1),
+		httpbatch.MaxPending(1),
+		httpbatch.Retry(1, 200*time.Millisecond, 200*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "first\n")
+	e.receive(t)
+
+	write(t, w, "second\n", "third\n")
+	assert.Equal(t, uint64(1), w.Stats().DroppedEvents)
+
+	assert.Equal(t, "first\n", e.receive(t).body)
+	assert.Equal(t, "second\n", e.receive(t).body)
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	cases := map[string]httpbatch.Option{
+		"zero max events":   httpbatch.MaxEvents(0),
+		"zero max bytes":    httpbatch.MaxBytes(0),
+		"zero interval":     httpbatch.Interval(0),
+		"negative interval": httpbatch.Interval(-time.Second),
+		"negative pending":  httpbatch.MaxPending(-1),
+		"negative retries":  httpbatch.Retry(-1, time.Millisecond, time.Second),
+		"negative backoff":  httpbatch.Retry(1, -time.Millisecond, time.Second),
+		"inverted backoff":  httpbatch.Retry(1, time.Second, time.Millisecond),
+		"nil client":        httpbatch.Client(nil),
+	}
+
+	for name, opt := range cases {
+		_, err := httpbatch.New("http://127.0.0.1/", opt)
+		assert.Error(t, err, name)
+	}
+}
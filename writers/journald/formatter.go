@@ -0,0 +1,168 @@
+//go:build linux
+// +build linux
+
+// Package journald provides formatter rendering logo events in native
+// protocol of systemd-journald and writer sending them to journal:
+//
+//	w, err := journald.Dial()
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer w.Close()
+//
+//	logger := log.NewLogger(log.LevelVerbose, w, journald.Formatter{})
+package journald
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/syslog"
+)
+
+// maxFieldNameLength is a maximal length of journal field name.
+const maxFieldNameLength = 64
+
+// reservedFields are fields set by Formatter itself, extra keys clashing
+// with them are prefixed with EXTRA_.
+var reservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+var defaultIdentifier = filepath.Base(os.Args[0])
+
+// Formatter renders events as journal entries in native protocol.
+//
+// Message becomes MESSAGE field, PRIORITY is derived from level, caller
+// is stored in CODE_FILE, CODE_LINE and CODE_FUNC fields. Extra keys
+// become journal fields: they are upper-cased, characters other than
+// letters, digits and underscores are replaced with underscores and
+// nested maps are flattened with underscore as separator.
+//
+// Newline terminating the last field is omitted, as Logger appends it.
+type Formatter struct {
+	// Identifier is a value of SYSLOG_IDENTIFIER field, program name
+	// is used if empty.
+	Identifier string
+	// Priorities maps logo levels to priorities the same way as
+	// syslog.Formatter.Severities does. syslog.DefaultSeverities is used
+	// if table is empty.
+	Priorities map[log.Level]syslog.Severity
+}
+
+// Format converts given event to journal entry.
+func (f Formatter) Format(event log.Event) (string, error) {
+	formatted, err := f.AppendFormat(nil, event)
+	return string(formatted), err
+}
+
+// AppendFormat appends journal entry representing given event to dst.
+func (f Formatter) AppendFormat(dst []byte, event log.Event) ([]byte, error) {
+	if event.Message == nil {
+		return dst, nil
+	}
+
+	priority := syslog.Formatter{Severities: f.Priorities}.Severity(event.Level)
+
+	identifier := f.Identifier
+	if identifier == "" {
+		identifier = defaultIdentifier
+	}
+
+	dst = appendField(dst, "MESSAGE", log.TextValue(event.Message))
+	dst = appendField(dst, "PRIORITY", strconv.Itoa(int(priority)))
+	dst = appendField(dst, "SYSLOG_IDENTIFIER", identifier)
+
+	if event.Caller != nil {
+		dst = appendField(dst, "CODE_FILE", event.Caller.File)
+		dst = appendField(dst, "CODE_LINE", strconv.Itoa(event.Caller.Line))
+		dst = appendField(dst, "CODE_FUNC", event.Caller.Function)
+	}
+
+	log.FlattenExtra(event.Extra, "_", func(key string, value interface{}) {
+		name := FieldName(key)
+		if name == "" {
+			return
+		}
+
+		if reservedFields[name] {
+			name = "EXTRA_" + name
+		}
+
+		dst = appendField(dst, name, log.TextValue(value))
+	})
+
+	// Logger terminates the last field with newline.
+	return dst[:len(dst)-1], nil
+}
+
+// FieldName converts given key to valid journal field name: it's
+// upper-cased, characters other than letters, digits and underscores are
+// replaced with underscores, leading underscores are removed (such fields
+// are reserved for journal), name starting with digit is prefixed with X
+// and name is truncated to 64 characters. Empty string is returned if
+// nothing is left.
+func FieldName(key string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+
+		if c == '_' && b.Len() == 0 {
+			continue
+		}
+
+		if c >= '0' && c <= '9' && b.Len() == 0 {
+			b.WriteByte('X')
+		}
+
+		b.WriteByte(c)
+	}
+
+	name := b.String()
+	if len(name) > maxFieldNameLength {
+		name = name[:maxFieldNameLength]
+	}
+
+	return name
+}
+
+// appendField appends field in native protocol. Values containing
+// newlines are prefixed with their length in binary form.
+func appendField(dst []byte, name, value string) []byte {
+	dst = append(dst, name...)
+
+	if strings.IndexByte(value, '\n') < 0 {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+
+		return append(dst, '\n')
+	}
+
+	var size [8]byte
+
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+
+	dst = append(dst, '\n')
+	dst = append(dst, size[:]...)
+	dst = append(dst, value...)
+
+	return append(dst, '\n')
+}
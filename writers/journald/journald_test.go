@@ -0,0 +1,189 @@
+//go:build linux
+// +build linux
+
+package journald_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/journald"
+	"github.com/tomakado/logo/writers/syslog"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	formatter := journald.Formatter{Identifier: "app"}
+
+	event := log.Event{
+		Time:    time.Now(),
+		Level:   log.LevelImportant,
+		Message: "user logged in",
+		Extra: log.Extra{
+			"user-id": 42,
+			"request": log.Extra{"path": "/login"},
+			"message": "clash",
+			"_hidden": true,
+		},
+		Caller: &log.Frame{Function: "main.main", File: "/app/main.go", Line: 7},
+	}
+
+	formatted, err := formatter.Format(event)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"MESSAGE=user logged in",
+		"PRIORITY=5",
+		"SYSLOG_IDENTIFIER=app",
+		"CODE_FILE=/app/main.go",
+		"CODE_LINE=7",
+		"CODE_FUNC=main.main",
+		"HIDDEN=true",
+		"EXTRA_MESSAGE=clash",
+		"REQUEST_PATH=/login",
+		"USER_ID=42",
+	}, "\n"), formatted)
+
+	t.Run("multiline value", func(t *testing.T) {
+		formatted, err := formatter.Format(log.Event{Level: log.LevelVerbose, Message: "multi\nline"})
+		assert.NoError(t, err)
+
+		size := make([]byte, 8)
+		binary.LittleEndian.PutUint64(size, 10)
+
+		assert.Equal(t, "MESSAGE\n"+string(size)+"multi\nline\nPRIORITY=6\nSYSLOG_IDENTIFIER=app", formatted)
+	})
+
+	t.Run("custom priorities", func(t *testing.T) {
+		formatter := journald.Formatter{
+			Identifier: "app",
+			Priorities: map[log.Level]syslog.Severity{log.LevelImportant: syslog.SeverityError},
+		}
+
+		formatted, err := formatter.Format(log.Event{Level: log.LevelImportant, Message: "boom"})
+		assert.NoError(t, err)
+		assert.Equal(t, "MESSAGE=boom\nPRIORITY=3\nSYSLOG_IDENTIFIER=app", formatted)
+	})
+
+	t.Run("empty message", func(t *testing.T) {
+		formatted, err := formatter.Format(log.NewEvent(log.LevelVerbose, nil, nil))
+		assert.NoError(t, err)
+		assert.Empty(t, formatted)
+	})
+}
+
+func TestFieldName(t *testing.T) {
+	cases := map[string]string{
+		"user_id":               "USER_ID",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"__trusted":             "TRUSTED",
+		"1st":                   "X1ST",
+		"___":                   "",
+		"ключ":                  "",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+
+	for key, expected := range cases {
+		assert.Equal(t, expected, journald.FieldName(key), key)
+	}
+}
+
+// listen creates unixgram socket standing in for journald.
+func listen(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "journald")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	path := filepath.Join(dir, "socket")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn, path
+}
+
+// fGetSeals is fcntl command returning seals of memfd.
+const fGetSeals = 0x40a
+
+// receive reads a single entry sent to journald either as datagram or
+// as passed file descriptor, reporting whether descriptor is sealed.
+func receive(t *testing.T, conn *net.UnixConn) (entry string, passed, sealed bool) {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	buf := make([]byte, 64<<10)
+	oob := make([]byte, syscall.CmsgSpace(4))
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+
+	if oobn == 0 {
+		return string(buf[:n]), false, false
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+
+	file := os.NewFile(uintptr(fds[0]), "entry")
+	defer file.Close()
+
+	seals, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), fGetSeals, 0)
+
+	_, err = file.Seek(0, 0)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+
+	return string(data), true, errno == 0 && seals != 0
+}
+
+func TestWriter(t *testing.T) {
+	conn, path := listen(t)
+
+	w, err := journald.DialSocket(path)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	logger := log.NewLogger(log.LevelVerbose, w, journald.Formatter{Identifier: "app"})
+	logger.VerboseX(context.Background(), "hello", log.Extra{"k": "v"})
+
+	entry, passed, _ := receive(t, conn)
+	assert.False(t, passed)
+	assert.Equal(t, "MESSAGE=hello\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\nK=v\n", entry)
+
+	t.Run("large entry", func(t *testing.T) {
+		message := strings.Repeat("x", 1<<20)
+		logger.Verbose(context.Background(), message)
+
+		entry, passed, sealed := receive(t, conn)
+		assert.True(t, passed)
+		assert.True(t, sealed, "memfd must be sealed")
+		assert.Equal(t, "MESSAGE="+message+"\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\n", entry)
+	})
+}
@@ -0,0 +1,187 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// DefaultSocket is a path of journald native protocol socket.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// shmDir is a directory of temporary files passed to journald when
+// memfd isn't available.
+const shmDir = "/dev/shm"
+
+// Flags of memfd_create and fcntl missing in syscall package.
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+
+	fAddSeals   = 0x409
+	fSealSeal   = 0x1
+	fSealShrink = 0x2
+	fSealGrow   = 0x4
+	fSealWrite  = 0x8
+)
+
+// sysMemfdCreate holds numbers of memfd_create syscall, syscall package
+// doesn't define it for all architectures.
+var sysMemfdCreate = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// Writer sends journal entries to journald, every Write sends a single
+// entry as datagram. Entries exceeding datagram size limit are written to
+// sealed memfd and its descriptor is passed to journald instead, as
+// sd_journal_send does. Unlinked temporary file in /dev/shm is used if
+// memfd isn't supported by kernel.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	mx   sync.Mutex
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// Dial connects to journald at DefaultSocket.
+func Dial() (*Writer, error) {
+	return DialSocket(DefaultSocket)
+}
+
+// DialSocket connects to journald listening on socket at given path.
+func DialSocket(path string) (*Writer, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	// Unconnected socket is required to pass descriptors along
+	// with datagrams.
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		conn: conn,
+		addr: &net.UnixAddr{Name: path, Net: "unixgram"},
+	}, nil
+}
+
+// Write sends given journal entry to journald.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	_, err := w.conn.WriteToUnix(p, w.addr)
+	if err == nil {
+		return len(p), nil
+	}
+
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return 0, err
+	}
+
+	if err := w.sendFile(p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes connection to journald.
+func (w *Writer) Close() error {
+	return w.conn.Close()
+}
+
+// sendFile writes entry to sealed memfd or unlinked temporary file and
+// passes its descriptor to journald.
+func (w *Writer) sendFile(p []byte) error {
+	file, err := memfd(p)
+	if err != nil {
+		if file, err = shmFile(p); err != nil {
+			return err
+		}
+	}
+
+	defer file.Close()
+
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), w.addr)
+
+	return err
+}
+
+// memfd creates memory file holding p and seals it, so journald can
+// read it without copying.
+func memfd(p []byte) (*os.File, error) {
+	nr, ok := sysMemfdCreate[runtime.GOARCH]
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+
+	name, err := syscall.BytePtrFromString("journald")
+	if err != nil {
+		return nil, err
+	}
+
+	fd, _, errno := syscall.Syscall(nr, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	file := os.NewFile(fd, "journald")
+
+	if _, err := file.Write(p); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	seals := uintptr(fSealSeal | fSealShrink | fSealGrow | fSealWrite)
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, seals); errno != 0 {
+		_ = file.Close()
+		return nil, errno
+	}
+
+	return file, nil
+}
+
+// shmFile writes p to unlinked temporary file in /dev/shm. Files in other
+// directories aren't accepted by journald unless sealed.
+func shmFile(p []byte) (*os.File, error) {
+	file, err := ioutil.TempFile(shmDir, "journal.")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(file.Name()); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Write(p); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,677 @@
+// Package network provides writer shipping newline-delimited events to
+// log collector over TCP, UDP or Unix sockets:
+//
+//	w, err := network.New("tcp", "collector.local:5170")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer w.Close()
+//
+//	logger := log.NewSinkLogger(log.LevelVerbose, w.Sinks(&log.JSONFormatter{}, log.LevelImportant))
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/tomakado/logo/log"
+)
+
+// ErrClosed is returned on writing to closed Writer.
+var ErrClosed = errors.New("network: writer is closed")
+
+// Default settings of Writer.
+const (
+	DefaultBufferSize   = 1 << 20
+	DefaultMinBackoff   = 100 * time.Millisecond
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultDialTimeout  = 5 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	DefaultFlushTimeout = 5 * time.Second
+)
+
+// leastBackoff is the least delay between reconnection attempts, smaller
+// ones are raised to it to avoid busy redialing.
+const leastBackoff = 10 * time.Millisecond
+
+// Option configures Writer.
+type Option func(*Writer)
+
+// BufferSize limits size of in-memory buffer holding events while
+// collector is unavailable, DefaultBufferSize by default.
+func BufferSize(size int) Option {
+	return func(w *Writer) {
+		w.bufferSize = size
+	}
+}
+
+// Spill makes writer store events in temporary file in given directory
+// once in-memory buffer is full. Size of file is limited by maxSize bytes.
+// File is removed on Close, so spilled events don't survive restart.
+func Spill(dir string, maxSize int64) Option {
+	return func(w *Writer) {
+		w.spillDir = dir
+		w.spillSize = maxSize
+	}
+}
+
+// TLS makes writer use TLS over stream connections with given config.
+func TLS(config *tls.Config) Option {
+	return func(w *Writer) {
+		w.tlsConfig = config
+	}
+}
+
+// Backoff sets minimal and maximal delays between reconnection attempts,
+// delay is doubled after every failed attempt. Delays below 10ms are
+// raised to it. DefaultMinBackoff and DefaultMaxBackoff are used by default.
+func Backoff(min, max time.Duration) Option {
+	return func(w *Writer) {
+		w.minBackoff = min
+		w.maxBackoff = max
+	}
+}
+
+// Timeouts sets timeouts of dialing and writing single event.
+// DefaultDialTimeout and DefaultWriteTimeout are used by default.
+func Timeouts(dial, write time.Duration) Option {
+	return func(w *Writer) {
+		w.dialTimeout = dial
+		w.writeTimeout = write
+	}
+}
+
+// FlushTimeout limits time Close spends on sending buffered events,
+// DefaultFlushTimeout by default.
+func FlushTimeout(timeout time.Duration) Option {
+	return func(w *Writer) {
+		w.flushTimeout = timeout
+	}
+}
+
+// OnError sets handler of connection and spill errors, such errors are
+// ignored by default.
+func OnError(handler func(err error)) Option {
+	return func(w *Writer) {
+		w.onError = handler
+	}
+}
+
+// Stats holds counters of Writer.
+type Stats struct {
+	// SentBytes is a number of bytes written to connections.
+	SentBytes uint64
+	// DroppedBytes is a number of bytes dropped because buffer was full
+	// or writer was closed before they were sent.
+	DroppedBytes uint64
+	// BufferedBytes is a number of bytes waiting to be sent.
+	BufferedBytes uint64
+	// Connects is a number of established connections.
+	Connects uint64
+	// DialErrors is a number of failed connection attempts.
+	DialErrors uint64
+}
+
+// message is a single buffered write.
+type message struct {
+	data     []byte
+	priority bool
+}
+
+// Writer is io.WriteCloser shipping events to collector. Write never
+// blocks on network: events are buffered in memory (and spilled to disk
+// if configured) and sent in background, connection is reestablished
+// with exponential backoff. When buffer is full, writes are dropped
+// and counted in Stats, except writes made through Priority writer which
+// evict ordinary buffered events first.
+//
+// Events are sent at least once only if connection breaks in a way
+// detected by writer, data accepted by kernel before collector crashes
+// is lost.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	network string
+	addr    string
+
+	bufferSize   int
+	spillDir     string
+	spillSize    int64
+	tlsConfig    *tls.Config
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	flushTimeout time.Duration
+	onError      func(err error)
+
+	mx       sync.Mutex
+	queue    []message
+	queued   int
+	inFlight bool
+	spool    *spool
+	closed   bool
+
+	conn   net.Conn
+	wake   chan struct{}
+	ctx    context.Context // cancelled when flush timeout expires
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	stats Stats
+}
+
+// New creates a new instance of Writer shipping events to given address.
+// Supported networks are tcp, tcp4, tcp6, udp, udp4, udp6, unix and
+// unixgram. Connection is established in background.
+func New(network, addr string, opts ...Option) (*Writer, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("network: unsupported network %q", network)
+	}
+
+	w := &Writer{
+		network:      network,
+		addr:         addr,
+		bufferSize:   DefaultBufferSize,
+		minBackoff:   DefaultMinBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		dialTimeout:  DefaultDialTimeout,
+		writeTimeout: DefaultWriteTimeout,
+		flushTimeout: DefaultFlushTimeout,
+		onError:      func(error) {},
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	if w.minBackoff < leastBackoff {
+		w.minBackoff = leastBackoff
+	}
+
+	if w.maxBackoff < w.minBackoff {
+		w.maxBackoff = w.minBackoff
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Write buffers given event to be sent to collector.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.write(p, false)
+}
+
+// Priority returns writer sharing buffer and connection with w, whose
+// writes evict ordinary buffered events instead of being dropped when
+// buffer is full.
+func (w *Writer) Priority() io.Writer {
+	return priorityWriter{w}
+}
+
+// Sinks returns logger sinks writing events of given level and above
+// through Priority writer and other events through w.
+func (w *Writer) Sinks(formatter log.Formatter, priority log.Level) []log.Sink {
+	return []log.Sink{
+		{Output: w.Priority(), Formatter: formatter, Level: priority},
+		{
+			Output:    w,
+			Formatter: formatter,
+			Filter: func(e *log.Event) bool {
+				return !e.Level.Gte(priority)
+			},
+		},
+	}
+}
+
+// Stats returns current values of writer's counters.
+func (w *Writer) Stats() Stats {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	stats := w.stats
+	stats.BufferedBytes = uint64(w.queued)
+
+	if w.spool != nil {
+		stats.BufferedBytes += uint64(w.spool.size())
+	}
+
+	return stats
+}
+
+// Close stops accepting events, waits until buffered events are sent or
+// flush timeout expires and closes connection.
+func (w *Writer) Close() error {
+	w.mx.Lock()
+
+	if w.closed {
+		w.mx.Unlock()
+		return ErrClosed
+	}
+
+	w.closed = true
+
+	w.mx.Unlock()
+
+	w.notify()
+
+	timer := time.AfterFunc(w.flushTimeout, w.abort)
+	defer timer.Stop()
+
+	<-w.done
+	w.cancel()
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.stats.DroppedBytes += uint64(w.queued)
+	w.queue, w.queued = nil, 0
+
+	var err error
+
+	if w.spool != nil {
+		w.stats.DroppedBytes += uint64(w.spool.size())
+		err = w.spool.close()
+		w.spool = nil
+	}
+
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	return err
+}
+
+// priorityWriter writes events through Writer with priority.
+type priorityWriter struct {
+	w *Writer
+}
+
+// Write buffers given event to be sent to collector with priority.
+func (p priorityWriter) Write(data []byte) (int, error) {
+	return p.w.write(data, true)
+}
+
+// write copies given data to buffer.
+func (w *Writer) write(p []byte, priority bool) (int, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.enqueue(message{data: append([]byte(nil), p...), priority: priority})
+	w.notify()
+
+	return len(p), nil
+}
+
+// enqueue puts message to memory buffer or spill file, or drops it.
+// Once events are spilled, new ones go to spill file too to keep order,
+// except priority ones which are put to memory buffer ahead of spilled
+// events rather than dropped when spill file is full.
+func (w *Writer) enqueue(msg message) {
+	spilling := w.spool != nil && w.spool.size() > 0
+
+	if !spilling && w.fits(msg) {
+		w.push(msg)
+		return
+	}
+
+	if w.spillDir != "" {
+		if err := w.spill(msg.data); err == nil {
+			return
+		} else if !errors.Is(err, errSpoolFull) {
+			w.onError(fmt.Errorf("spill event: %w", err))
+		}
+	}
+
+	if spilling && msg.priority && w.fits(msg) {
+		w.push(msg)
+		return
+	}
+
+	w.stats.DroppedBytes += uint64(len(msg.data))
+}
+
+// fits reports whether message fits memory buffer, ordinary messages are
+// evicted to make room for priority one.
+func (w *Writer) fits(msg message) bool {
+	size := len(msg.data)
+
+	return w.queued+size <= w.bufferSize || (msg.priority && w.evict(size))
+}
+
+// push appends message to memory buffer.
+func (w *Writer) push(msg message) {
+	w.queue = append(w.queue, msg)
+	w.queued += len(msg.data)
+}
+
+// evict drops ordinary messages from memory buffer, the oldest first,
+// until there is room for given number of bytes. Nothing is dropped if
+// it's impossible to free enough room.
+func (w *Writer) evict(size int) bool {
+	start := 0
+	if w.inFlight {
+		start = 1
+	}
+
+	free := w.bufferSize - w.queued
+	for _, msg := range w.queue[start:] {
+		if free >= size {
+			break
+		}
+
+		if !msg.priority {
+			free += len(msg.data)
+		}
+	}
+
+	if free < size {
+		return false
+	}
+
+	kept := w.queue[:start]
+
+	for _, msg := range w.queue[start:] {
+		if w.bufferSize-w.queued < size && !msg.priority {
+			w.queued -= len(msg.data)
+			w.stats.DroppedBytes += uint64(len(msg.data))
+
+			continue
+		}
+
+		kept = append(kept, msg)
+	}
+
+	w.queue = kept
+
+	return true
+}
+
+// spill writes data to spill file creating it if needed.
+func (w *Writer) spill(data []byte) error {
+	if w.spool == nil {
+		s, err := newSpool(w.spillDir, w.spillSize)
+		if err != nil {
+			return err
+		}
+
+		w.spool = s
+	}
+
+	return w.spool.write(data)
+}
+
+// notify wakes sender up.
+func (w *Writer) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run sends buffered events until writer is closed and buffer is drained
+// or flush timeout expires.
+func (w *Writer) run() {
+	defer close(w.done)
+
+	backoff := w.minBackoff
+
+	for {
+		data, ok := w.next()
+		if !ok {
+			return
+		}
+
+		if w.conn == nil {
+			if err := w.connect(); err != nil {
+				if w.ctx.Err() != nil {
+					return
+				}
+
+				w.onError(fmt.Errorf("connect: %w", err))
+
+				if !w.sleep(backoff) {
+					return
+				}
+
+				if backoff *= 2; backoff > w.maxBackoff {
+					backoff = w.maxBackoff
+				}
+
+				continue
+			}
+
+			backoff = w.minBackoff
+		}
+
+		if err := w.send(data); err != nil {
+			if w.ctx.Err() == nil {
+				w.onError(fmt.Errorf("send event: %w", err))
+			}
+
+			w.mx.Lock()
+			_ = w.conn.Close()
+			w.conn = nil
+			w.mx.Unlock()
+
+			continue
+		}
+
+		w.commit(len(data))
+	}
+}
+
+// next waits for buffered event and returns it without removing from
+// buffer. False is returned if writer is closed and buffer is drained
+// or flush timeout expired.
+func (w *Writer) next() ([]byte, bool) {
+	for {
+		w.mx.Lock()
+
+		if len(w.queue) > 0 {
+			w.inFlight = true
+			data := w.queue[0].data
+			w.mx.Unlock()
+
+			return data, true
+		}
+
+		if w.spool != nil && w.spool.size() > 0 {
+			data, err := w.spool.peek()
+			if err == nil {
+				w.mx.Unlock()
+				return data, true
+			}
+
+			w.onError(fmt.Errorf("read spilled event: %w", err))
+			w.stats.DroppedBytes += uint64(w.spool.size())
+			w.spool.reset()
+		}
+
+		closed := w.closed
+		w.mx.Unlock()
+
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-w.wake:
+		case <-w.ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// commit removes sent event from buffer.
+func (w *Writer) commit(n int) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.stats.SentBytes += uint64(n)
+
+	if w.inFlight {
+		w.inFlight = false
+		w.queued -= len(w.queue[0].data)
+		w.queue[0] = message{}
+		w.queue = w.queue[1:]
+
+		return
+	}
+
+	w.spool.pop()
+}
+
+// sleep waits for given duration, false is returned if flush timeout
+// expired meanwhile.
+func (w *Writer) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
+}
+
+// abort stops sending once flush timeout expires, dial and write in
+// progress are interrupted.
+func (w *Writer) abort() {
+	w.cancel()
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.conn != nil {
+		_ = w.conn.SetWriteDeadline(time.Now())
+	}
+}
+
+// connect establishes connection to collector.
+func (w *Writer) connect() error {
+	dialer := &net.Dialer{Timeout: w.dialTimeout}
+
+	conn, err := dialer.DialContext(w.ctx, w.network, w.addr)
+	if err == nil && w.tlsConfig != nil && isStream(w.network) {
+		conn, err = w.handshake(conn)
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if err != nil {
+		w.stats.DialErrors++
+		return err
+	}
+
+	w.stats.Connects++
+
+	if isStream(w.network) {
+		// Collector isn't expected to send anything, so reading detects
+		// closed connection before next event is written to it.
+		go func() {
+			_, _ = io.Copy(ioutil.Discard, conn)
+			_ = conn.Close()
+		}()
+	}
+
+	w.conn = conn
+
+	return nil
+}
+
+// handshake establishes TLS session over given connection within dial
+// timeout, it's interrupted once flush timeout expires.
+func (w *Writer) handshake(conn net.Conn) (net.Conn, error) {
+	config := w.tlsConfig
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(w.addr)
+		if err != nil {
+			host = w.addr
+		}
+
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	if w.dialTimeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(w.dialTimeout))
+	}
+
+	stop := make(chan struct{})
+
+	go func() {
+		select {
+		case <-w.ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	tlsConn := tls.Client(conn, config)
+	err := tlsConn.Handshake()
+
+	close(stop)
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
+
+// send writes event to connection. Deadline set by abort may be replaced
+// here, so context is checked after setting deadline.
+func (w *Writer) send(data []byte) error {
+	if w.writeTimeout > 0 {
+		if err := w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+			return err
+		}
+	}
+
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := w.conn.Write(data)
+
+	return err
+}
+
+// isStream returns true for stream-oriented networks.
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
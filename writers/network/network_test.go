@@ -0,0 +1,473 @@
+package network_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/network"
+)
+
+// collector accepts connections and collects lines received over them.
+type collector struct {
+	ln    net.Listener
+	lines chan string
+
+	mx    sync.Mutex
+	conns []net.Conn
+}
+
+func newCollector(t *testing.T, ln net.Listener) *collector {
+	t.Helper()
+
+	c := &collector{ln: ln, lines: make(chan string, 100)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			c.mx.Lock()
+			c.conns = append(c.conns, conn)
+			c.mx.Unlock()
+
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					c.lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+		c.dropConns()
+	})
+
+	return c
+}
+
+func listenCollector(t *testing.T, addr string) *collector {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+
+	return newCollector(t, ln)
+}
+
+// receive waits for the next line.
+func (c *collector) receive(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case line := <-c.lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("line is not received")
+		return ""
+	}
+}
+
+// dropConns closes accepted connections.
+func (c *collector) dropConns() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, conn := range c.conns {
+		_ = conn.Close()
+	}
+
+	c.conns = nil
+}
+
+// freeAddr returns address nobody listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	return addr
+}
+
+func write(t *testing.T, w *network.Writer, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		n, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+}
+
+func TestWriter_TCP(t *testing.T) {
+	c := listenCollector(t, "127.0.0.1:0")
+
+	w, err := network.New("tcp", c.ln.Addr().String())
+	require.NoError(t, err)
+
+	logger := log.NewLogger(log.LevelVerbose, w, &log.JSONFormatter{})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		logger.Verbose(context.Background(), msg)
+	}
+
+	for _, expected := range []string{"one", "two", "three"} {
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(c.receive(t)), &decoded))
+		assert.Equal(t, expected, decoded["message"])
+	}
+
+	require.NoError(t, w.Close())
+
+	stats := w.Stats()
+	assert.Greater(t, stats.SentBytes, uint64(0))
+	assert.Equal(t, uint64(0), stats.DroppedBytes)
+	assert.Equal(t, uint64(0), stats.BufferedBytes)
+	assert.Equal(t, uint64(1), stats.Connects)
+}
+
+func TestWriter_Reconnect(t *testing.T) {
+	addr := freeAddr(t)
+
+	w, err := network.New("tcp", addr, network.Backoff(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "one\n", "two\n")
+
+	assert.Eventually(t, func() bool {
+		return w.Stats().DialErrors > 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(8), w.Stats().BufferedBytes)
+
+	c := listenCollector(t, addr)
+	assert.Equal(t, "one", c.receive(t))
+	assert.Equal(t, "two", c.receive(t))
+
+	// Collector restart.
+	c.dropConns()
+	time.Sleep(50 * time.Millisecond)
+
+	write(t, w, "three\n")
+	assert.Equal(t, "three", c.receive(t))
+	assert.Equal(t, uint64(2), w.Stats().Connects)
+}
+
+func TestWriter_BufferFull(t *testing.T) {
+	w, err := network.New(
+		"tcp", freeAddr(t),
+		network.BufferSize(20),
+		network.Backoff(time.Hour, time.Hour),
+		network.FlushTimeout(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	write(t, w, "aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n")
+	assert.Equal(t, uint64(5), w.Stats().DroppedBytes)
+
+	n, err := w.Priority().Write([]byte("PPPP\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	stats := w.Stats()
+	assert.Equal(t, uint64(10), stats.DroppedBytes)
+	assert.Equal(t, uint64(20), stats.BufferedBytes)
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, uint64(30), w.Stats().DroppedBytes)
+}
+
+func TestWriter_Spill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	addr := freeAddr(t)
+
+	w, err := network.New(
+		"tcp", addr,
+		network.BufferSize(10),
+		network.Spill(dir, 1<<20),
+		network.Backoff(10*time.Millisecond, 10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	lines := []string{"line 1", "line 2", "line 3", "line 4", "line 5"}
+	for _, line := range lines {
+		write(t, w, line+"\n")
+	}
+
+	assert.Equal(t, uint64(35), w.Stats().BufferedBytes)
+	assert.Equal(t, uint64(0), w.Stats().DroppedBytes)
+
+	c := listenCollector(t, addr)
+	for _, line := range lines {
+		assert.Equal(t, line, c.receive(t))
+	}
+
+	require.NoError(t, w.Close())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	t.Run("spill limit", func(t *testing.T) {
+		w, err := network.New(
+			"tcp", freeAddr(t),
+			network.BufferSize(10),
+			network.Spill(dir, 22),
+			network.Backoff(time.Hour, time.Hour),
+			network.FlushTimeout(10*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		write(t, w, "line 1\n", "line 2\n", "line 3\n", "line 4\n")
+
+		stats := w.Stats()
+		assert.Equal(t, uint64(21), stats.BufferedBytes)
+		assert.Equal(t, uint64(7), stats.DroppedBytes)
+
+		require.NoError(t, w.Close())
+	})
+}
+
+func TestWriter_SpillPriority(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	addr := freeAddr(t)
+
+	w, err := network.New(
+		"tcp", addr,
+		network.BufferSize(10),
+		network.Spill(dir, 18),
+		network.Backoff(10*time.Millisecond, 10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "aaaa\n", "aaaa\n", "bbbb\n", "bbbb\n", "cccc\n")
+	assert.Equal(t, uint64(5), w.Stats().DroppedBytes)
+
+	_, err = w.Priority().Write([]byte("PPPP\n"))
+	require.NoError(t, err)
+
+	stats := w.Stats()
+	assert.Equal(t, uint64(10), stats.DroppedBytes)
+	assert.Equal(t, uint64(20), stats.BufferedBytes)
+
+	c := listenCollector(t, addr)
+	for _, expected := range []string{"aaaa", "PPPP", "bbbb", "bbbb"} {
+		assert.Equal(t, expected, c.receive(t))
+	}
+}
+
+func TestWriter_ZeroBackoff(t *testing.T) {
+	w, err := network.New(
+		"tcp", freeAddr(t),
+		network.Backoff(0, 0),
+		network.FlushTimeout(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	write(t, w, "event\n")
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Less(t, w.Stats().DialErrors, uint64(20))
+	require.NoError(t, w.Close())
+}
+
+func TestWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	w, err := network.New("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "hello\n")
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(buf[:n]))
+}
+
+func TestWriter_TLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+
+	defer server.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	require.NoError(t, err)
+
+	c := newCollector(t, ln)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	w, err := network.New("tcp", ln.Addr().String(), network.TLS(&tls.Config{RootCAs: roots}))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	write(t, w, "secret\n")
+	assert.Equal(t, "secret", c.receive(t))
+}
+
+func TestWriter_Sinks(t *testing.T) {
+	c := listenCollector(t, "127.0.0.1:0")
+
+	w, err := network.New("tcp", c.ln.Addr().String())
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	logger := log.NewSinkLogger(log.LevelVerbose, w.Sinks(log.SimpleTextFormatter, log.LevelImportant))
+	logger.Verbose(context.Background(), "verbose")
+	logger.Important(context.Background(), "important")
+
+	assert.Contains(t, c.receive(t), "verbose")
+	assert.Contains(t, c.receive(t), "important")
+
+	select {
+	case line := <-c.lines:
+		t.Fatalf("unexpected line %q", line)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// silentListener accepts connections and neither reads nor writes.
+func silentListener(t *testing.T) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var (
+		mx    sync.Mutex
+		conns []net.Conn
+	)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			mx.Lock()
+			conns = append(conns, conn)
+			mx.Unlock()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+
+		mx.Lock()
+		defer mx.Unlock()
+
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	})
+
+	return ln
+}
+
+func TestWriter_CloseFlushTimeout(t *testing.T) {
+	t.Run("blocked write", func(t *testing.T) {
+		ln := silentListener(t)
+
+		w, err := network.New(
+			"tcp", ln.Addr().String(),
+			network.BufferSize(64<<20),
+			network.FlushTimeout(100*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		event := make([]byte, 1<<20)
+		for i := 0; i < 32; i++ {
+			write(t, w, string(event))
+		}
+
+		assert.Eventually(t, func() bool {
+			return w.Stats().SentBytes > 0
+		}, time.Second, 5*time.Millisecond)
+
+		start := time.Now()
+		require.NoError(t, w.Close())
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+		assert.Greater(t, w.Stats().DroppedBytes, uint64(0))
+	})
+
+	t.Run("blocked handshake", func(t *testing.T) {
+		ln := silentListener(t)
+
+		w, err := network.New(
+			"tcp", ln.Addr().String(),
+			network.TLS(&tls.Config{}),
+			network.FlushTimeout(100*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		write(t, w, "event\n")
+		time.Sleep(50 * time.Millisecond)
+
+		start := time.Now()
+		require.NoError(t, w.Close())
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+		assert.Equal(t, uint64(0), w.Stats().Connects)
+	})
+}
+
+func TestWriter_Close(t *testing.T) {
+	_, err := network.New("ip", "127.0.0.1")
+	assert.Error(t, err)
+
+	c := listenCollector(t, "127.0.0.1:0")
+
+	w, err := network.New("tcp", c.ln.Addr().String())
+	require.NoError(t, err)
+
+	write(t, w, "flushed\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "flushed", c.receive(t))
+
+	_, err = w.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, network.ErrClosed)
+	assert.ErrorIs(t, w.Close(), network.ErrClosed)
+}
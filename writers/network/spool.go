@@ -0,0 +1,113 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+)
+
+// errSpoolFull is returned on writing to spool which reached its limit.
+var errSpoolFull = errors.New("spool is full")
+
+// spool is a FIFO queue of events stored in temporary file. Every event
+// is prefixed with its length as 32-bit big endian integer. File is
+// truncated only when all events are read, so its size limits total
+// size of events spilled since then.
+type spool struct {
+	file    *os.File
+	maxSize int64
+	readAt  int64
+	writeAt int64
+	pending int64
+	head    []byte
+}
+
+// newSpool creates spool in temporary file in given directory.
+func newSpool(dir string, maxSize int64) (*spool, error) {
+	file, err := ioutil.TempFile(dir, "network-spool-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &spool{file: file, maxSize: maxSize}, nil
+}
+
+// size returns number of bytes of events stored in spool.
+func (s *spool) size() int64 {
+	return s.pending
+}
+
+// write appends event to spool.
+func (s *spool) write(data []byte) error {
+	record := int64(len(data) + 4)
+	if s.maxSize > 0 && s.writeAt+record > s.maxSize {
+		return errSpoolFull
+	}
+
+	buf := make([]byte, record)
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+
+	if _, err := s.file.WriteAt(buf, s.writeAt); err != nil {
+		return err
+	}
+
+	s.writeAt += record
+	s.pending += int64(len(data))
+
+	return nil
+}
+
+// peek returns the oldest event without removing it from spool.
+func (s *spool) peek() ([]byte, error) {
+	if s.head != nil {
+		return s.head, nil
+	}
+
+	var size [4]byte
+	if _, err := s.file.ReadAt(size[:], s.readAt); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := s.file.ReadAt(data, s.readAt+4); err != nil {
+		return nil, err
+	}
+
+	s.head = data
+
+	return data, nil
+}
+
+// pop removes the oldest event from spool. File is truncated once
+// spool becomes empty.
+func (s *spool) pop() {
+	if s.head == nil {
+		return
+	}
+
+	s.readAt += int64(len(s.head) + 4)
+	s.pending -= int64(len(s.head))
+	s.head = nil
+
+	if s.size() == 0 {
+		s.reset()
+	}
+}
+
+// reset drops all events from spool.
+func (s *spool) reset() {
+	s.readAt, s.writeAt, s.pending, s.head = 0, 0, 0, nil
+	_ = s.file.Truncate(0)
+}
+
+// close closes and removes spool file.
+func (s *spool) close() error {
+	err := s.file.Close()
+	if removeErr := os.Remove(s.file.Name()); err == nil {
+		err = removeErr
+	}
+
+	return err
+}
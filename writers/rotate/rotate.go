@@ -0,0 +1,503 @@
+// Package rotate provides file writer which rotates files by size and
+// time, so it can be passed to logger as output:
+//
+//	w, err := rotate.New("/var/log/app.log", rotate.MaxSize(100<<20), rotate.MaxBackups(7), rotate.Compress())
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer w.Close()
+//
+//	logger := log.NewLogger(log.LevelVerbose, w, &log.JSONFormatter{})
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is a layout of time in backup file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// ErrClosed is returned on writing to closed Writer.
+var ErrClosed = errors.New("rotate: writer is closed")
+
+// Option configures Writer.
+type Option func(*Writer)
+
+// MaxSize makes writer rotate file before it grows beyond given size
+// in bytes. Single write larger than size is written to empty file as is.
+func MaxSize(size int64) Option {
+	return func(w *Writer) {
+		w.maxSize = size
+	}
+}
+
+// Interval makes writer rotate file when wall clock crosses multiple of
+// given interval, e.g. every hour at minute zero for time.Hour. Intervals
+// are aligned to UTC.
+func Interval(interval time.Duration) Option {
+	return func(w *Writer) {
+		w.interval = interval
+	}
+}
+
+// MaxBackups limits number of rotated files kept on disk, the oldest ones
+// are removed. All backups are kept by default.
+func MaxBackups(n int) Option {
+	return func(w *Writer) {
+		w.maxBackups = n
+	}
+}
+
+// Compress makes writer gzip rotated files in background.
+func Compress() Option {
+	return func(w *Writer) {
+		w.compress = true
+	}
+}
+
+// OnError sets handler of errors occurred in background: while compressing
+// or removing backups, or reopening file on signal. Such errors are
+// ignored by default.
+func OnError(handler func(err error)) Option {
+	return func(w *Writer) {
+		w.onError = handler
+	}
+}
+
+// Writer is io.WriteCloser writing to file and rotating it. Rotated files
+// are renamed to name-<UTC time>.ext next to the file, e.g.
+// app-2021-05-17T10-30-00.000.log. Files are rotated on write, so every
+// event written by logger lands in a single file.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	mx       sync.Mutex
+	filename string
+	file     *os.File
+	size     int64
+	period   time.Time
+	closed   bool
+
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+	onError    func(err error)
+	signals    []os.Signal
+
+	mill   chan struct{}
+	milled chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New creates a new instance of Writer appending to given file.
+// File and its directory are created if they don't exist.
+func New(filename string, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		filename: filename,
+		onError:  func(error) {},
+		mill:     make(chan struct{}, 1),
+		milled:   make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	go w.millRun()
+
+	if len(w.signals) > 0 {
+		w.watchSignals()
+	}
+
+	return w, nil
+}
+
+// Write writes given bytes to file rotating it beforehand if needed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return 0, err
+	}
+
+	if now := time.Now(); w.shouldRotate(now, len(p)) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Rotate rotates file immediately.
+func (w *Writer) Rotate() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return err
+	}
+
+	return w.rotate(time.Now())
+}
+
+// Reopen closes file and opens it again by name. It's used to pick up
+// new file after external tool such as logrotate has moved the old one.
+func (w *Writer) Reopen() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("close file: %w", err)
+		}
+
+		w.file = nil
+	}
+
+	return w.open()
+}
+
+// Sync commits written data to stable storage.
+func (w *Writer) Sync() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return err
+	}
+
+	return w.file.Sync()
+}
+
+// Close closes file and waits for background compression and removal
+// of backups to finish.
+func (w *Writer) Close() error {
+	w.mx.Lock()
+
+	if w.closed {
+		w.mx.Unlock()
+		return ErrClosed
+	}
+
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+
+	w.closed = true
+
+	w.mx.Unlock()
+
+	close(w.stop)
+	close(w.mill)
+	w.wg.Wait()
+	<-w.milled
+
+	return err
+}
+
+// ensureOpen returns ErrClosed if writer is closed and opens file again
+// if it was lost because of failed rotation.
+func (w *Writer) ensureOpen() error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	if w.file == nil {
+		return w.open()
+	}
+
+	return nil
+}
+
+// shouldRotate returns true if writing n bytes at given time requires
+// rotation.
+func (w *Writer) shouldRotate(now time.Time, n int) bool {
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(n) > w.maxSize {
+		return true
+	}
+
+	return w.interval > 0 && !w.periodOf(now).Equal(w.period)
+}
+
+// periodOf returns start of rotation interval containing given time.
+func (w *Writer) periodOf(t time.Time) time.Time {
+	if w.interval <= 0 {
+		return time.Time{}
+	}
+
+	return t.Truncate(w.interval)
+}
+
+// open opens file for appending.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.period = w.periodOf(info.ModTime())
+
+	return nil
+}
+
+// rotate renames current file to backup name and opens a new one.
+// File is reopened even if renaming fails, so writing can go on.
+func (w *Writer) rotate(now time.Time) error {
+	err := w.file.Close()
+	w.file = nil
+
+	if err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	renameErr := os.Rename(w.filename, w.backupName(now))
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.period = w.periodOf(now)
+
+	if renameErr != nil {
+		w.onError(fmt.Errorf("rename file: %w", renameErr))
+		return nil
+	}
+
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backupName returns name of backup file for rotation at given time,
+// which doesn't clash with existing files.
+func (w *Writer) backupName(t time.Time) string {
+	dir, prefix, ext := w.backupParts()
+
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+
+		_, err := os.Stat(name)
+		if os.IsNotExist(err) {
+			_, err = os.Stat(name + ".gz")
+		}
+
+		if os.IsNotExist(err) {
+			return name
+		}
+
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// backupParts returns directory, name prefix and extension of backups.
+func (w *Writer) backupParts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(w.filename)
+	ext = filepath.Ext(base)
+
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backup is a rotated file.
+type backup struct {
+	name string
+	time time.Time
+}
+
+// backups returns rotated files, the newest first.
+func (w *Writer) backups() ([]backup, error) {
+	dir, prefix, ext := w.backupParts()
+	if dir == "" {
+		dir = "."
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimPrefix(name, prefix)
+		switch {
+		case strings.HasSuffix(ts, ext+".gz"):
+			ts = strings.TrimSuffix(ts, ext+".gz")
+		case strings.HasSuffix(ts, ext):
+			ts = strings.TrimSuffix(ts, ext)
+		default:
+			continue
+		}
+
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{name: filepath.Join(dir, name), time: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	return backups, nil
+}
+
+// millRun removes and compresses backups after every rotation until
+// writer is closed.
+func (w *Writer) millRun() {
+	defer close(w.milled)
+
+	for range w.mill {
+		w.millOnce()
+	}
+}
+
+// millOnce removes backups exceeding limit and compresses the rest.
+func (w *Writer) millOnce() {
+	if w.maxBackups <= 0 && !w.compress {
+		return
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		w.onError(fmt.Errorf("list backups: %w", err))
+		return
+	}
+
+	if w.maxBackups > 0 && len(backups) > w.maxBackups {
+		for _, b := range backups[w.maxBackups:] {
+			if err := os.Remove(b.name); err != nil {
+				w.onError(fmt.Errorf("remove backup: %w", err))
+			}
+		}
+
+		backups = backups[:w.maxBackups]
+	}
+
+	if !w.compress {
+		return
+	}
+
+	for _, b := range backups {
+		if strings.HasSuffix(b.name, ".gz") {
+			continue
+		}
+
+		if err := compressFile(b.name); err != nil {
+			w.onError(fmt.Errorf("compress backup: %w", err))
+		}
+	}
+}
+
+// compressFile replaces given file with its gzipped copy.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+// watchSignals reopens file every time one of writer's signals is
+// received until writer is closed.
+func (w *Writer) watchSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, w.signals...)
+
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ch:
+				if err := w.Reopen(); err != nil && !errors.Is(err, ErrClosed) {
+					w.onError(fmt.Errorf("reopen file: %w", err))
+				}
+			}
+		}
+	}()
+}
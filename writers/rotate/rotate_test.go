@@ -0,0 +1,267 @@
+package rotate_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/rotate"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+// backups returns names of rotated files in dir, the oldest first.
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, file := range files {
+		if file.Name() != "app.log" {
+			names = append(names, file.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(name)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestWriter_MaxSize(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.MaxSize(25))
+	require.NoError(t, err)
+
+	for _, line := range []string{"line 0001\n", "line 0002\n", "line 0003\n", "line 0004\n", "line 0005\n"} {
+		n, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		assert.Equal(t, len(line), n)
+	}
+
+	require.NoError(t, w.Close())
+
+	names := backups(t, dir)
+	require.Len(t, names, 2)
+
+	assert.True(t, strings.HasPrefix(names[0], "app-"))
+	assert.True(t, strings.HasSuffix(names[0], ".log"))
+	assert.Equal(t, "line 0001\nline 0002\n", readFile(t, filepath.Join(dir, names[0])))
+	assert.Equal(t, "line 0003\nline 0004\n", readFile(t, filepath.Join(dir, names[1])))
+	assert.Equal(t, "line 0005\n", readFile(t, filename))
+
+	t.Run("write larger than max size", func(t *testing.T) {
+		dir := tempDir(t)
+		filename := filepath.Join(dir, "app.log")
+
+		w, err := rotate.New(filename, rotate.MaxSize(5))
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("long line\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Empty(t, backups(t, dir))
+		assert.Equal(t, "long line\n", readFile(t, filename))
+	})
+}
+
+func TestWriter_Interval(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.Interval(100*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	time.Sleep(110 * time.Millisecond)
+
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	names := backups(t, dir)
+	require.Len(t, names, 1)
+
+	assert.Equal(t, "first\n", readFile(t, filepath.Join(dir, names[0])))
+	assert.Equal(t, "second\n", readFile(t, filename))
+}
+
+func TestWriter_MaxBackups(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.MaxBackups(2))
+	require.NoError(t, err)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		require.NoError(t, w.Rotate())
+	}
+
+	require.NoError(t, w.Close())
+
+	names := backups(t, dir)
+	require.Len(t, names, 2)
+
+	assert.Equal(t, "three\n", readFile(t, filepath.Join(dir, names[0])))
+	assert.Equal(t, "four\n", readFile(t, filepath.Join(dir, names[1])))
+}
+
+func TestWriter_Compress(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.Compress(), rotate.OnError(func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	}))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("compressed\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	require.NoError(t, w.Close())
+
+	names := backups(t, dir)
+	require.Len(t, names, 1)
+	require.True(t, strings.HasSuffix(names[0], ".log.gz"))
+
+	file, err := os.Open(filepath.Join(dir, names[0]))
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "compressed\n", string(data))
+}
+
+func TestWriter_Reopen(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, w.Reopen())
+
+	_, err = w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "before\n", readFile(t, filename+".1"))
+	assert.Equal(t, "after\n", readFile(t, filename))
+}
+
+func TestWriter_Close(t *testing.T) {
+	w, err := rotate.New(filepath.Join(tempDir(t), "app.log"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("hello\n"))
+	assert.ErrorIs(t, err, rotate.ErrClosed)
+	assert.ErrorIs(t, w.Rotate(), rotate.ErrClosed)
+	assert.ErrorIs(t, w.Reopen(), rotate.ErrClosed)
+	assert.ErrorIs(t, w.Close(), rotate.ErrClosed)
+}
+
+func TestWriter_Logger(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.MaxSize(1024))
+	require.NoError(t, err)
+
+	logger := log.NewLogger(log.LevelVerbose, w, &log.JSONFormatter{})
+
+	const (
+		goroutines = 8
+		events     = 50
+	)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < events; j++ {
+				logger.VerboseX(context.Background(), "hello", log.Extra{"goroutine": i, "event": j})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	require.NoError(t, w.Close())
+
+	names := append(backups(t, dir), "app.log")
+	assert.Greater(t, len(names), 1)
+
+	var lines int
+
+	for _, name := range names {
+		file, err := os.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var decoded map[string]interface{}
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &decoded))
+
+			lines++
+		}
+
+		require.NoError(t, scanner.Err())
+		require.NoError(t, file.Close())
+	}
+
+	assert.Equal(t, goroutines*events, lines)
+}
@@ -0,0 +1,22 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package rotate
+
+import (
+	"os"
+	"syscall"
+)
+
+// ReopenOnSignal makes writer reopen file every time one of given signals
+// is received, which is how logrotate notifies programs after moving their
+// files. SIGHUP is used if no signals are given.
+func ReopenOnSignal(sigs ...os.Signal) Option {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	return func(w *Writer) {
+		w.signals = sigs
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package rotate_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/writers/rotate"
+)
+
+func TestReopenOnSignal(t *testing.T) {
+	dir := tempDir(t)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := rotate.New(filename, rotate.ReopenOnSignal())
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "before\n", readFile(t, filename+".1"))
+	assert.Equal(t, "after\n", readFile(t, filename))
+}
@@ -0,0 +1,341 @@
+// Package syslog provides formatter rendering logo events as syslog
+// messages and writer delivering them to syslog daemon:
+//
+//	w, err := syslog.Dial("udp", "127.0.0.1:514")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer w.Close()
+//
+//	logger := log.NewLogger(log.LevelVerbose, w, &syslog.Formatter{AppName: "app"})
+package syslog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/tomakado/logo/log"
+)
+
+// Protocol is a syslog message format.
+type Protocol int
+
+// Supported message formats.
+const (
+	// RFC5424 is a modern syslog format with structured data.
+	RFC5424 Protocol = iota
+	// RFC3164 is a legacy BSD syslog format.
+	RFC3164
+)
+
+// Facility is a syslog facility.
+type Facility uint8
+
+// Syslog facilities.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// Severity is a syslog severity.
+type Severity uint8
+
+// Syslog severities.
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// DefaultSeverities maps logo levels to syslog severities by default.
+var DefaultSeverities = map[log.Level]Severity{
+	log.LevelVerbose:   SeverityInfo,
+	log.LevelImportant: SeverityNotice,
+}
+
+// DefaultSDID is an ID of structured data element holding extra.
+const DefaultSDID = "logo@32473"
+
+var (
+	defaultHostname = func() string {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return ""
+		}
+
+		return hostname
+	}()
+	defaultAppName = filepath.Base(os.Args[0])
+	defaultProcID  = strconv.Itoa(os.Getpid())
+)
+
+// Formatter renders events as syslog messages. Zero value produces
+// RFC 5424 messages with user facility, host name, program name and
+// process ID of current process.
+//
+// Extra is rendered as structured data element with nested maps
+// flattened to dotted keys, caller is added as "caller" parameter.
+// RFC 3164 has no structured data, so the element is appended to message.
+type Formatter struct {
+	// Protocol is a message format, RFC5424 by default.
+	Protocol Protocol
+	// Facility is a facility of messages. Zero value means FacilityUser,
+	// as kernel facility isn't available to user processes.
+	Facility Facility
+	// Hostname, AppName and ProcID override host name, program name and
+	// process ID of current process.
+	Hostname string
+	AppName  string
+	ProcID   string
+	// MsgID is an optional type of messages used by RFC 5424.
+	MsgID string
+	// SDID is an ID of structured data element, DefaultSDID is used if empty.
+	SDID string
+	// Severities maps logo levels to syslog severities. Event level is
+	// mapped to severity of the greatest level in table not exceeding it,
+	// levels lower than all levels in table are mapped to severity of the
+	// lowest one. DefaultSeverities is used if table is empty.
+	Severities map[log.Level]Severity
+}
+
+// Format converts given event to syslog message.
+func (f Formatter) Format(event log.Event) (string, error) {
+	formatted, err := f.AppendFormat(nil, event)
+	return string(formatted), err
+}
+
+// AppendFormat appends syslog message representing given event to dst.
+func (f Formatter) AppendFormat(dst []byte, event log.Event) ([]byte, error) {
+	if event.Message == nil {
+		return dst, nil
+	}
+
+	if f.Protocol == RFC3164 {
+		return f.appendRFC3164(dst, event), nil
+	}
+
+	return f.appendRFC5424(dst, event), nil
+}
+
+// Severity returns syslog severity of given level.
+func (f Formatter) Severity(level log.Level) Severity {
+	table := DefaultSeverities
+	if len(f.Severities) > 0 {
+		table = f.Severities
+	}
+
+	var (
+		found, lowest     bool
+		best, lowestLevel log.Level
+	)
+
+	for l := range table {
+		if !level.Gte(l) {
+			if !lowest || lowestLevel.Gt(l) {
+				lowest, lowestLevel = true, l
+			}
+
+			continue
+		}
+
+		if !found || l.Gt(best) {
+			found, best = true, l
+		}
+	}
+
+	if found {
+		return table[best]
+	}
+
+	return table[lowestLevel]
+}
+
+// priority returns PRI part of message.
+func (f Formatter) priority(level log.Level) int {
+	facility := f.Facility
+	if facility == FacilityKern {
+		facility = FacilityUser
+	}
+
+	return int(facility)*8 + int(f.Severity(level))
+}
+
+// appendRFC5424 appends message in RFC 5424 format.
+func (f Formatter) appendRFC5424(dst []byte, event log.Event) []byte {
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(f.priority(event.Level)), 10)
+	dst = append(dst, ">1 "...)
+
+	if event.Time.IsZero() {
+		dst = append(dst, '-')
+	} else {
+		dst = event.Time.AppendFormat(dst, "2006-01-02T15:04:05.000000Z07:00")
+	}
+
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, valueOrDefault(f.Hostname, defaultHostname), 255)
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, valueOrDefault(f.AppName, defaultAppName), 48)
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, valueOrDefault(f.ProcID, defaultProcID), 128)
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, f.MsgID, 32)
+	dst = append(dst, ' ')
+
+	if hasStructuredData(event) {
+		dst = f.appendStructuredData(dst, event)
+	} else {
+		dst = append(dst, '-')
+	}
+
+	dst = append(dst, ' ')
+
+	return append(dst, log.TextValue(event.Message)...)
+}
+
+// appendRFC3164 appends message in RFC 3164 format.
+func (f Formatter) appendRFC3164(dst []byte, event log.Event) []byte {
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(f.priority(event.Level)), 10)
+	dst = append(dst, '>')
+	dst = event.Time.AppendFormat(dst, time.Stamp)
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, valueOrDefault(f.Hostname, defaultHostname), 255)
+	dst = append(dst, ' ')
+	dst = appendHeaderField(dst, valueOrDefault(f.AppName, defaultAppName), 32)
+	dst = append(dst, '[')
+	dst = appendHeaderField(dst, valueOrDefault(f.ProcID, defaultProcID), 128)
+	dst = append(dst, "]: "...)
+	dst = append(dst, log.TextValue(event.Message)...)
+
+	if hasStructuredData(event) {
+		dst = append(dst, ' ')
+		dst = f.appendStructuredData(dst, event)
+	}
+
+	return dst
+}
+
+// hasStructuredData returns true if event has caller or extra.
+func hasStructuredData(event log.Event) bool {
+	return event.Caller != nil || len(event.Extra) > 0
+}
+
+// appendStructuredData appends structured data element holding caller
+// and extra of event.
+func (f Formatter) appendStructuredData(dst []byte, event log.Event) []byte {
+	dst = append(dst, '[')
+	dst = appendName(dst, valueOrDefault(f.SDID, DefaultSDID))
+
+	if event.Caller != nil {
+		dst = appendParam(dst, "caller", event.Caller.String())
+	}
+
+	log.FlattenExtra(event.Extra, ".", func(key string, value interface{}) {
+		dst = appendParam(dst, key, log.TextValue(value))
+	})
+
+	return append(dst, ']')
+}
+
+// appendParam appends structured data parameter.
+func appendParam(dst []byte, name, value string) []byte {
+	dst = append(dst, ' ')
+	dst = appendName(dst, name)
+	dst = append(dst, '=', '"')
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+
+	return append(dst, '"')
+}
+
+// appendName appends SD-ID or PARAM-NAME replacing forbidden characters
+// with underscores and truncating it to 32 characters.
+func appendName(dst []byte, name string) []byte {
+	if name == "" {
+		return append(dst, '_')
+	}
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c < 33 || c > 126 || c == '=' || c == ']' || c == '"':
+			dst = append(dst, '_')
+		default:
+			dst = append(dst, c)
+		}
+	}
+
+	return dst
+}
+
+// appendHeaderField appends header field replacing non-printable
+// characters with underscores and truncating it to given length.
+// Empty field is rendered as NILVALUE.
+func appendHeaderField(dst []byte, value string, maxLen int) []byte {
+	if value == "" {
+		return append(dst, '-')
+	}
+
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < 33 || c > 126 {
+			dst = append(dst, '_')
+		} else {
+			dst = append(dst, c)
+		}
+	}
+
+	return dst
+}
+
+// valueOrDefault returns given value or default one if value is empty.
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
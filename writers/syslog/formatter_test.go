@@ -0,0 +1,114 @@
+package syslog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/syslog"
+)
+
+func testEvent() log.Event {
+	return log.Event{
+		Time:    time.Date(2021, 5, 17, 10, 30, 0, 123456789, time.UTC),
+		Level:   log.LevelImportant,
+		Message: "user logged in",
+		Extra: log.Extra{
+			"user":    "bob",
+			"request": log.Extra{"id": 42},
+			"note":    `say "hi" [x]`,
+		},
+	}
+}
+
+func TestFormatter_RFC5424(t *testing.T) {
+	formatter := syslog.Formatter{
+		Facility: syslog.FacilityLocal0,
+		Hostname: "host",
+		AppName:  "app",
+		ProcID:   "123",
+		MsgID:    "AUTH",
+	}
+
+	formatted, err := formatter.Format(testEvent())
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`<133>1 2021-05-17T10:30:00.123456Z host app 123 AUTH [logo@32473 note="say \"hi\" [x\]" request.id="42" user="bob"] user logged in`,
+		formatted,
+	)
+
+	t.Run("without extra", func(t *testing.T) {
+		event := log.Event{Level: log.LevelVerbose, Message: errors.New("boom")}
+
+		formatted, err := syslog.Formatter{Hostname: "host", AppName: "my app", ProcID: "1"}.Format(event)
+		assert.NoError(t, err)
+		assert.Equal(t, `<14>1 - host my_app 1 - - boom`, formatted)
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		event := log.Event{
+			Time:    time.Date(2021, 5, 17, 10, 30, 0, 0, time.UTC),
+			Level:   log.LevelVerbose,
+			Message: "hello",
+			Caller:  &log.Frame{Function: "main.main", File: "main.go", Line: 7},
+		}
+
+		formatted, err := syslog.Formatter{Hostname: "host", AppName: "app", ProcID: "1", SDID: "app@1"}.Format(event)
+		assert.NoError(t, err)
+		assert.Equal(t, `<14>1 2021-05-17T10:30:00.000000Z host app 1 - [app@1 caller="main.go:7"] hello`, formatted)
+	})
+
+	t.Run("empty message", func(t *testing.T) {
+		formatted, err := formatter.Format(log.NewEvent(log.LevelVerbose, nil, nil))
+		assert.NoError(t, err)
+		assert.Empty(t, formatted)
+	})
+}
+
+func TestFormatter_RFC3164(t *testing.T) {
+	formatter := syslog.Formatter{
+		Protocol: syslog.RFC3164,
+		Facility: syslog.FacilityDaemon,
+		Hostname: "host",
+		AppName:  "app",
+		ProcID:   "123",
+	}
+
+	formatted, err := formatter.Format(testEvent())
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`<29>May 17 10:30:00 host app[123]: user logged in [logo@32473 note="say \"hi\" [x\]" request.id="42" user="bob"]`,
+		formatted,
+	)
+}
+
+func TestFormatter_Severity(t *testing.T) {
+	levelDebug := log.NewLevel(5, "DEBUG")
+	levelError := log.NewLevel(30, "ERROR")
+
+	t.Run("default", func(t *testing.T) {
+		formatter := syslog.Formatter{}
+
+		assert.Equal(t, syslog.SeverityInfo, formatter.Severity(levelDebug))
+		assert.Equal(t, syslog.SeverityInfo, formatter.Severity(log.LevelVerbose))
+		assert.Equal(t, syslog.SeverityNotice, formatter.Severity(log.LevelImportant))
+		assert.Equal(t, syslog.SeverityNotice, formatter.Severity(levelError))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		formatter := syslog.Formatter{Severities: map[log.Level]syslog.Severity{
+			log.LevelVerbose:   syslog.SeverityDebug,
+			log.LevelImportant: syslog.SeverityWarning,
+			levelError:         syslog.SeverityError,
+		}}
+
+		assert.Equal(t, syslog.SeverityDebug, formatter.Severity(levelDebug))
+		assert.Equal(t, syslog.SeverityDebug, formatter.Severity(log.LevelVerbose))
+		assert.Equal(t, syslog.SeverityWarning, formatter.Severity(log.NewLevel(25, "WARNING")))
+		assert.Equal(t, syslog.SeverityError, formatter.Severity(levelError))
+	})
+}
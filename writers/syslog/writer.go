@@ -0,0 +1,147 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// localSockets are paths of local syslog sockets on different systems.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// ErrNoLocalSyslog is returned by Dial if no local syslog socket is found.
+var ErrNoLocalSyslog = errors.New("syslog: local syslog socket not found")
+
+// Writer delivers syslog messages to syslog daemon, every Write sends
+// a single message with trailing newline trimmed. Messages are sent as
+// datagrams over unixgram and udp networks and with octet counting
+// framing (RFC 6587) over stream networks such as tcp and unix.
+// Connection is reestablished once if write fails.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	mx      sync.Mutex
+	network string
+	addr    string
+	conn    net.Conn
+	stream  bool
+}
+
+// Dial connects to syslog daemon at given address. Local syslog socket
+// is used if network and address are empty.
+func Dial(network, addr string) (*Writer, error) {
+	w := &Writer{network: network, addr: addr}
+
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write sends given message to syslog daemon.
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\n")
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.conn != nil {
+		if err := w.send(msg); err == nil {
+			return len(p), nil
+		}
+
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+
+	if err := w.send(msg); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes connection to syslog daemon.
+func (w *Writer) Close() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
+
+// send writes message to connection with framing required by network.
+func (w *Writer) send(msg []byte) error {
+	if !w.stream {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+
+	frame := make([]byte, 0, len(msg)+8)
+	frame = strconv.AppendInt(frame, int64(len(msg)), 10)
+	frame = append(frame, ' ')
+	frame = append(frame, msg...)
+
+	_, err := w.conn.Write(frame)
+
+	return err
+}
+
+// connect establishes connection to syslog daemon.
+func (w *Writer) connect() error {
+	if w.network == "" && w.addr == "" {
+		return w.connectLocal()
+	}
+
+	conn, err := net.Dial(w.network, w.addr)
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	w.stream = isStream(w.network)
+
+	return nil
+}
+
+// connectLocal connects to the first available local syslog socket.
+func (w *Writer) connectLocal() error {
+	for _, path := range localSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err != nil {
+				continue
+			}
+
+			w.conn = conn
+			w.stream = isStream(network)
+
+			return nil
+		}
+	}
+
+	return ErrNoLocalSyslog
+}
+
+// isStream returns true for stream-oriented networks.
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,156 @@
+package syslog_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/logo/log"
+	"github.com/tomakado/logo/writers/syslog"
+)
+
+var formatter = syslog.Formatter{Hostname: "host", AppName: "app", ProcID: "1"}
+
+// readDatagram reads a single datagram from conn.
+func readDatagram(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+func TestWriter_Unixgram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unix datagram sockets are not supported: %v", err)
+	}
+
+	defer conn.Close()
+
+	w, err := syslog.Dial("unixgram", path)
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	logger := log.NewLogger(log.LevelVerbose, w, formatter)
+	logger.Important(context.Background(), "hello")
+
+	msg := readDatagram(t, conn)
+	assert.True(t, strings.HasPrefix(msg, "<13>1 "), msg)
+	assert.True(t, strings.HasSuffix(msg, " host app 1 - - hello"), msg)
+}
+
+func TestWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	w, err := syslog.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	n, err := w.Write([]byte("<14>1 - host app 1 - - first\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 29, n)
+
+	assert.Equal(t, "<14>1 - host app 1 - - first", readDatagram(t, conn))
+}
+
+func TestWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer ln.Close()
+
+	frames := make(chan string, 3)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+
+				for {
+					size, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+
+					n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+					if err != nil {
+						return
+					}
+
+					buf := make([]byte, n)
+					if _, err := io.ReadFull(r, buf); err != nil {
+						return
+					}
+
+					frames <- string(buf)
+				}
+			}()
+		}
+	}()
+
+	w, err := syslog.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	logger := log.NewLogger(log.LevelVerbose, w, formatter)
+	logger.VerboseX(context.Background(), "multi\nline", log.Extra{"k": "v"})
+	logger.Verbose(context.Background(), "second")
+
+	for _, expected := range []string{
+		"host app 1 - [logo@32473 k=\"v\"] multi\nline",
+		"host app 1 - - second",
+	} {
+		select {
+		case frame := <-frames:
+			assert.True(t, strings.HasSuffix(frame, expected), frame)
+		case <-time.After(time.Second):
+			t.Fatal("frame is not received")
+		}
+	}
+}
+
+func TestDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = syslog.Dial("tcp", addr)
+	assert.Error(t, err)
+}